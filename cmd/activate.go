@@ -13,6 +13,17 @@ var activateCmd = &cli.Command{
 	Name:    "activate",
 	Aliases: []string{"a"},
 	Usage:   "Activate an environment",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "shell",
+			Value: "bash",
+			Usage: "Shell syntax of the output (bash, zsh, fish, nu, tcsh, xonsh)",
+		},
+		&cli.BoolFlag{
+			Name:  "stack",
+			Usage: "Keep the current environment on PATH behind the new one",
+		},
+	},
 	Action: func(ctx context.Context, cmd *cli.Command) error {
 		name := cmd.Args().First()
 		if name == "" {
@@ -23,7 +34,7 @@ var activateCmd = &cli.Command{
 			return fmt.Errorf("environment %s does not exist", name)
 		}
 
-		script, err := shell.GenerateActivateScript(name)
+		script, err := shell.GenerateActivateScript(cmd.String("shell"), name, cmd.Bool("stack"))
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/env"
+)
+
+var cacheCmd = &cli.Command{
+	Name:  "cache",
+	Usage: "Manage cached ephemeral environments",
+	Commands: []*cli.Command{
+		{
+			Name:  "prune",
+			Usage: "Remove ephemeral environments that have not been used for a while",
+			Flags: []cli.Flag{
+				&cli.DurationFlag{
+					Name:  "older-than",
+					Usage: "Remove envs last used longer ago than this (0 removes all)",
+					Value: 7 * 24 * time.Hour,
+				},
+			},
+			Action: cachePrune,
+		},
+	},
+}
+
+var cachePrune = func(ctx context.Context, cmd *cli.Command) error {
+	removed, err := env.PruneEphemeral(cmd.Duration("older-than"))
+	for _, e := range removed {
+		fmt.Printf("Removed %s (Python %s; %s)\n", e.Key, e.Meta.Python, describeSpecs(e.Meta.Packages))
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(removed) == 0 {
+		fmt.Println("No cached environments to remove")
+	}
+	return nil
+}
+
+func describeSpecs(specs []string) string {
+	if len(specs) == 0 {
+		return "no packages"
+	}
+	return strings.Join(specs, " ")
+}
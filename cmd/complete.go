@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/env"
+	"github.com/uda/uda/internal/shell"
+	"github.com/uda/uda/internal/uv"
+)
+
+// completeCmd is the hidden entry point the shell completion scripts call.
+// It receives the words after "uda", the last one being the word under the
+// cursor, and prints one candidate per line.
+var completeCmd = &cli.Command{
+	Name:            "__complete",
+	Hidden:          true,
+	SkipFlagParsing: true,
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		for _, candidate := range complete(ctx, cmd.Root(), cmd.Args().Slice()) {
+			fmt.Println(candidate)
+		}
+		return nil
+	},
+}
+
+type completer func(ctx context.Context, prefix string) []string
+
+// positionalCompleters complete the positional arguments of a command,
+// keyed by the command path below the root
+var positionalCompleters = map[string][]completer{
+	"activate":         {completeEnvs},
+	"remove":           {completeEnvs},
+	"shell":            {completeEnvs},
+	"diff":             {completeEnvs, completeEnvs},
+	"upgrade-python":   {completeEnvs, completeInstalledPythons},
+	"env vars set":     {completeEnvs},
+	"env vars unset":   {completeEnvs},
+	"env vars list":    {completeEnvs},
+	"python install":   {completeAvailablePythons},
+	"python uninstall": {completeInstalledPythons},
+	"python which":     {completeInstalledPythons},
+	"init":             {completeShells},
+}
+
+// flagCompleters complete flag values by flag name
+var flagCompleters = map[string]completer{
+	"env":    completeEnvs,
+	"python": completeInstalledPythons,
+	"shell":  completeShells,
+}
+
+// complete returns the candidates for the last of words
+func complete(ctx context.Context, root *cli.Command, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+
+	c := root
+	var path []string
+	positional := 0
+	var pendingFlag cli.Flag
+	for _, word := range words[:len(words)-1] {
+		if pendingFlag != nil {
+			pendingFlag = nil
+			continue
+		}
+		if strings.HasPrefix(word, "-") {
+			name, _, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			if f := findFlag(c, name); f != nil && takesValue(f) && !hasValue {
+				pendingFlag = f
+			}
+			continue
+		}
+		if sub := findCommand(c, word); sub != nil && positional == 0 {
+			c = sub
+			path = append(path, sub.Name)
+			continue
+		}
+		positional++
+	}
+
+	if pendingFlag != nil {
+		if fn, ok := flagCompleters[pendingFlag.Names()[0]]; ok {
+			return fn(ctx, current)
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(current, "-") {
+		var flags []string
+		for _, f := range c.VisibleFlags() {
+			for _, name := range f.Names() {
+				flag := "--" + name
+				if len(name) == 1 {
+					flag = "-" + name
+				}
+				flags = append(flags, flag)
+			}
+		}
+		return filterPrefix(flags, current)
+	}
+
+	if positional == 0 && len(c.VisibleCommands()) > 0 {
+		var names []string
+		for _, sub := range c.VisibleCommands() {
+			names = append(names, sub.Name)
+		}
+		return filterPrefix(names, current)
+	}
+
+	completers := positionalCompleters[strings.Join(path, " ")]
+	if positional < len(completers) {
+		return completers[positional](ctx, current)
+	}
+	return nil
+}
+
+func findCommand(c *cli.Command, name string) *cli.Command {
+	for _, sub := range c.VisibleCommands() {
+		for _, n := range sub.Names() {
+			if n == name {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+func findFlag(c *cli.Command, name string) cli.Flag {
+	for _, f := range c.VisibleFlags() {
+		for _, n := range f.Names() {
+			if n == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+func takesValue(f cli.Flag) bool {
+	d, ok := f.(cli.DocGenerationFlag)
+	return ok && d.TakesValue()
+}
+
+func filterPrefix(candidates []string, prefix string) []string {
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+func completeEnvs(ctx context.Context, prefix string) []string {
+	envs, err := env.List()
+	if err != nil {
+		return nil
+	}
+	return filterPrefix(envs, prefix)
+}
+
+func completeShells(ctx context.Context, prefix string) []string {
+	return filterPrefix(shell.Names(), prefix)
+}
+
+func completeInstalledPythons(ctx context.Context, prefix string) []string {
+	return completePythons(ctx, prefix, true)
+}
+
+func completeAvailablePythons(ctx context.Context, prefix string) []string {
+	return completePythons(ctx, prefix, false)
+}
+
+func completePythons(ctx context.Context, prefix string, onlyInstalled bool) []string {
+	pythons, err := uv.ListPythons(ctx, "", onlyInstalled)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var versions []string
+	for _, p := range pythons {
+		if !seen[p.Version] {
+			seen[p.Version] = true
+			versions = append(versions, p.Version)
+		}
+	}
+	return filterPrefix(versions, prefix)
+}
@@ -3,20 +3,40 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/config"
 	"github.com/uda/uda/internal/env"
+	"github.com/uda/uda/internal/project"
 	"github.com/uda/uda/internal/uv"
 )
 
 var createCmd = &cli.Command{
-	Name:    "create",
-	Aliases: []string{"c"},
-	Usage:   "Create a new Python environment",
+	Name:      "create",
+	Aliases:   []string{"c"},
+	Usage:     "Create a new Python environment",
+	ArgsUsage: "<name> [python=<version>] [package...]",
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:  "python",
-			Usage: "Python version (e.g., 3.11)",
+			Usage: "Python version or range (e.g., 3.11 or \">=3.10,<3.13\")",
+		},
+		&cli.StringFlag{
+			Name:  "project",
+			Usage: "Project directory to read pyproject.toml or requirements*.txt from",
+		},
+		&cli.StringSliceFlag{
+			Name:  "extra",
+			Usage: "Optional dependency group of the project to install (repeatable)",
+		},
+		&cli.StringFlag{
+			Name:  "template",
+			Usage: "Template from config.toml providing Python version, packages and env vars",
+		},
+		&cli.BoolFlag{
+			Name:  "no-default-packages",
+			Usage: "Do not install create_default_packages from config.toml",
 		},
 	},
 	Action: func(ctx context.Context, cmd *cli.Command) error {
@@ -30,18 +50,179 @@ var createCmd = &cli.Command{
 			return fmt.Errorf("environment %s already exists", name)
 		}
 
+		cfg, err := config.LoadOrDefault()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
 		pythonVersion := cmd.String("python")
+		meta := &env.Meta{}
+
+		argPython, argPackages, err := parseCreateArgs(cmd.Args().Tail())
+		if err != nil {
+			return err
+		}
+		if argPython != "" {
+			if pythonVersion != "" && pythonVersion != argPython {
+				return fmt.Errorf("conflicting Python versions: --python %s and python=%s", pythonVersion, argPython)
+			}
+			pythonVersion = argPython
+		}
+
+		var packages []string
+		if !cmd.Bool("no-default-packages") {
+			packages = append(packages, cfg.CreateDefaultPackages...)
+		}
+
+		if templateName := cmd.String("template"); templateName != "" {
+			tmpl, ok := cfg.Templates[templateName]
+			if !ok || tmpl == nil {
+				return fmt.Errorf("template %s is not defined in %s", templateName, config.ConfigPath())
+			}
+			if pythonVersion == "" {
+				pythonVersion = tmpl.Python
+			}
+			packages = append(packages, tmpl.Packages...)
+			meta.Template = templateName
+			if len(tmpl.Env) > 0 {
+				meta.Vars = make(map[string]string, len(tmpl.Env))
+				for k, v := range tmpl.Env {
+					if err := env.ValidateVarName(k); err != nil {
+						return fmt.Errorf("template %s: %w", templateName, err)
+					}
+					meta.Vars[k] = v
+				}
+			}
+		}
+
+		// Read the project before touching anything so a bad project fails early
+		var proj *project.Project
+		var requirements []string
+		if dir := cmd.String("project"); dir != "" {
+			proj, err = project.Load(dir, cmd.StringSlice("extra"))
+			if err != nil {
+				return err
+			}
+			if pythonVersion == "" {
+				pythonVersion = proj.Python
+			}
+			packages = append(packages, proj.Dependencies...)
+			requirements = proj.RequirementFiles
+		} else if len(cmd.StringSlice("extra")) > 0 {
+			return fmt.Errorf("--extra requires --project")
+		}
+
+		packages = append(packages, argPackages...)
+
+		if pythonVersion == "" {
+			pythonVersion = cfg.DefaultPython
+		}
+
+		// Resolve ranges to a concrete version so uv installs and uses the same one
+		if pythonVersion != "" {
+			resolved, err := uv.ResolvePython(ctx, pythonVersion)
+			if err != nil {
+				return err
+			}
+			if resolved != pythonVersion {
+				fmt.Printf("Resolved Python %s to %s\n", pythonVersion, resolved)
+			}
+			pythonVersion = resolved
+		}
 
 		// Install Python if specified
 		if pythonVersion != "" {
 			fmt.Printf("Installing Python %s...\n", pythonVersion)
-			if err := uv.InstallPython(pythonVersion); err != nil {
+			if err := uv.InstallPython(ctx, pythonVersion); err != nil {
 				return fmt.Errorf("failed to install Python: %w", err)
 			}
 		}
 
 		// Create environment
 		fmt.Printf("Creating environment %s...\n", name)
-		return env.Create(name, pythonVersion)
+		if err := env.Create(ctx, name, pythonVersion); err != nil {
+			return err
+		}
+
+		meta.Python = pythonVersion
+		if version, err := env.PythonVersion(name); err == nil && version != "" {
+			meta.Python = version
+		}
+
+		// A half-provisioned env is worse than none, so undo the creation on failure
+		if err := installInitialPackages(ctx, name, packages, requirements, meta); err != nil {
+			fmt.Printf("Removing environment %s...\n", name)
+			if removeErr := env.Remove(name); removeErr != nil {
+				return fmt.Errorf("%w (and failed to remove %s: %v)", err, name, removeErr)
+			}
+			return err
+		}
+
+		if err := env.SaveMeta(name, meta); err != nil {
+			return err
+		}
+
+		if proj != nil {
+			if err := env.Bind(name, proj.Dir); err != nil {
+				return err
+			}
+			fmt.Printf("Bound %s to environment %s\n", proj.Dir, name)
+		}
+		return nil
 	},
 }
+
+// installInitialPackages installs the packages requested at creation in one step and records them
+func installInitialPackages(ctx context.Context, name string, packages []string, requirements []string, meta *env.Meta) error {
+	if len(packages) == 0 && len(requirements) == 0 {
+		return nil
+	}
+
+	args := []string{"pip", "install"}
+	args = append(args, packages...)
+	for _, file := range requirements {
+		args = append(args, "-r", file)
+	}
+
+	fmt.Printf("Installing packages into %s...\n", name)
+	if err := uv.RunUvWithPython(ctx, uv.GetPythonPath(name), args...); err != nil {
+		return fmt.Errorf("failed to install packages: %w", err)
+	}
+
+	meta.AddPackages(packages...)
+	meta.AddRequirements(requirements...)
+	return nil
+}
+
+// parseCreateArgs splits conda-style positional arguments into a Python
+// version (python=3.11) and pip requirement specs. Conda's single "=" pin
+// (numpy=1.26) is translated to the equivalent prefix match (numpy==1.26.*).
+func parseCreateArgs(args []string) (string, []string, error) {
+	var python string
+	var packages []string
+	for _, arg := range args {
+		spec := condaSpec(arg)
+		if env.RequirementName(spec) == "python" {
+			python = strings.TrimSpace(strings.TrimPrefix(spec[len("python"):], "=="))
+			if python == "" {
+				return "", nil, fmt.Errorf("python= requires a version, e.g. python=3.11")
+			}
+			continue
+		}
+		packages = append(packages, spec)
+	}
+	return python, packages, nil
+}
+
+func condaSpec(arg string) string {
+	i := strings.Index(arg, "=")
+	if i <= 0 || strings.ContainsAny(arg[i-1:i], "<>!~=") || strings.HasPrefix(arg[i:], "==") {
+		return arg
+	}
+
+	name, version := arg[:i], arg[i+1:]
+	if version == "" || strings.HasSuffix(version, ".*") || strings.ToLower(name) == "python" {
+		return name + "==" + version
+	}
+	return name + "==" + version + ".*"
+}
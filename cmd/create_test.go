@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCreateArgs(t *testing.T) {
+	python, packages, err := parseCreateArgs([]string{"python=3.11", "numpy=1.26", "requests>=2", "click"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if python != "3.11" {
+		t.Fatalf("unexpected python: %q", python)
+	}
+	expected := []string{"numpy==1.26.*", "requests>=2", "click"}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Fatalf("unexpected packages: %v", packages)
+	}
+
+	for _, arg := range []string{"python", "python=", "python=="} {
+		if _, _, err := parseCreateArgs([]string{arg, "numpy"}); err == nil || !strings.Contains(err.Error(), "requires a version") {
+			t.Fatalf("parseCreateArgs(%q): expected a missing version error, got %v", arg, err)
+		}
+	}
+}
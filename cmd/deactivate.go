@@ -12,8 +12,19 @@ var deactivateCmd = &cli.Command{
 	Name:    "deactivate",
 	Aliases: []string{"d"},
 	Usage:   "Deactivate current environment",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "shell",
+			Value: "bash",
+			Usage: "Shell syntax of the output (bash, zsh, fish, nu, tcsh, xonsh)",
+		},
+	},
 	Action: func(ctx context.Context, cmd *cli.Command) error {
-		script := shell.GenerateDeactivateScript()
+		script, err := shell.GenerateDeactivateScript(cmd.String("shell"))
+		if err != nil {
+			return err
+		}
+
 		fmt.Print(script)
 		return nil
 	},
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/envdiff"
+)
+
+var diffCmd = &cli.Command{
+	Name:      "diff",
+	Usage:     "Compare two environments, or an environment and a spec/lock file",
+	ArgsUsage: "<env|file> <env|file>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "Output the difference as JSON",
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		if cmd.Args().Len() != 2 {
+			return fmt.Errorf("two environments or files are required")
+		}
+
+		a, err := envdiff.Load(ctx, cmd.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		b, err := envdiff.Load(ctx, cmd.Args().Get(1))
+		if err != nil {
+			return err
+		}
+
+		result := envdiff.Compare(a, b)
+
+		if cmd.Bool("json") {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(result)
+		}
+
+		if result.Empty() {
+			fmt.Println("No differences found")
+			return nil
+		}
+		fmt.Print(result.Unified())
+		return nil
+	},
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/env"
+)
+
+var envCmd = &cli.Command{
+	Name:  "env",
+	Usage: "Environment configuration commands",
+	Commands: []*cli.Command{
+		{
+			Name:  "vars",
+			Usage: "Manage variables exported when an environment is activated",
+			Commands: []*cli.Command{
+				{
+					Name:      "set",
+					Usage:     "Set environment variables",
+					ArgsUsage: "<env> KEY=VALUE...",
+					Action:    envVarsSet,
+				},
+				{
+					Name:      "unset",
+					Usage:     "Remove environment variables",
+					ArgsUsage: "<env> KEY...",
+					Action:    envVarsUnset,
+				},
+				{
+					Name:      "list",
+					Aliases:   []string{"ls"},
+					Usage:     "List environment variables",
+					ArgsUsage: "<env>",
+					Action:    envVarsList,
+				},
+			},
+		},
+	},
+}
+
+// loadEnvMeta checks that the first argument names an existing env and loads its metadata
+func loadEnvMeta(cmd *cli.Command) (string, *env.Meta, error) {
+	name := cmd.Args().First()
+	if name == "" {
+		return "", nil, fmt.Errorf("environment name is required")
+	}
+
+	if !env.Exists(name) {
+		return "", nil, fmt.Errorf("environment %s does not exist", name)
+	}
+
+	meta, err := env.LoadMeta(name)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, meta, nil
+}
+
+var envVarsSet = func(ctx context.Context, cmd *cli.Command) error {
+	name, meta, err := loadEnvMeta(cmd)
+	if err != nil {
+		return err
+	}
+
+	assignments := cmd.Args().Tail()
+	if len(assignments) == 0 {
+		return fmt.Errorf("no variables specified")
+	}
+
+	if meta.Vars == nil {
+		meta.Vars = make(map[string]string)
+	}
+	for _, assignment := range assignments {
+		key, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			return fmt.Errorf("expected KEY=VALUE, got %q", assignment)
+		}
+		if err := env.ValidateVarName(key); err != nil {
+			return err
+		}
+		meta.Vars[key] = value
+	}
+
+	if err := env.SaveMeta(name, meta); err != nil {
+		return err
+	}
+	fmt.Printf("Reactivate %s to apply the changes\n", name)
+	return nil
+}
+
+var envVarsUnset = func(ctx context.Context, cmd *cli.Command) error {
+	name, meta, err := loadEnvMeta(cmd)
+	if err != nil {
+		return err
+	}
+
+	keys := cmd.Args().Tail()
+	if len(keys) == 0 {
+		return fmt.Errorf("no variables specified")
+	}
+
+	for _, key := range keys {
+		if _, ok := meta.Vars[key]; !ok {
+			return fmt.Errorf("variable %s is not set in %s", key, name)
+		}
+		delete(meta.Vars, key)
+	}
+
+	if err := env.SaveMeta(name, meta); err != nil {
+		return err
+	}
+	fmt.Printf("Reactivate %s to apply the changes\n", name)
+	return nil
+}
+
+var envVarsList = func(ctx context.Context, cmd *cli.Command) error {
+	_, meta, err := loadEnvMeta(cmd)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range meta.SortedVars() {
+		fmt.Printf("%s=%s\n", key, meta.Vars[key])
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/uda/uda/internal/config"
+	"github.com/uda/uda/internal/env"
 	"github.com/uda/uda/internal/shell"
 	"github.com/urfave/cli/v3"
 )
@@ -16,7 +18,15 @@ var initCmd = &cli.Command{
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:  "shell",
-			Usage: "Shell type (bash, zsh, fish)",
+			Usage: "Shell type (bash, zsh, fish, nu, tcsh, xonsh)",
+		},
+		&cli.BoolFlag{
+			Name:  "install",
+			Usage: "Add the integration to the shell's rc file (bash, zsh, fish)",
+		},
+		&cli.BoolFlag{
+			Name:  "reverse",
+			Usage: "Remove the integration added by --install",
 		},
 	},
 	Action: func(ctx context.Context, cmd *cli.Command) error {
@@ -46,7 +56,72 @@ var initCmd = &cli.Command{
 			return err
 		}
 
-		fmt.Print(shell.Init(shellType, executable))
+		if cmd.Bool("install") || cmd.Bool("reverse") {
+			return installInit(shellType, executable, cmd.Bool("reverse"))
+		}
+
+		script, err := shell.Init(shellType, executable)
+		if err != nil {
+			return err
+		}
+		fmt.Print(script)
+
+		cfg, err := config.LoadOrDefault()
+		if err != nil {
+			return err
+		}
+		if cfg.PipShim {
+			shim, err := shell.PipShim(shellType)
+			if err != nil {
+				return err
+			}
+			fmt.Print(shim)
+		}
+		if cfg.AutoActivate && cfg.DefaultEnv != "" {
+			if !env.Exists(cfg.DefaultEnv) {
+				fmt.Fprintf(os.Stderr, "uda: default_env %s does not exist, not activating it\n", cfg.DefaultEnv)
+				return nil
+			}
+			activate, err := shell.AutoActivate(shellType, cfg.DefaultEnv)
+			if err != nil {
+				return err
+			}
+			fmt.Print(activate)
+		}
 		return nil
 	},
 }
+
+// installInit adds or, with reverse, removes the marked init block in the
+// shell's rc file
+func installInit(shellType string, executable string, reverse bool) error {
+	rcFile, err := shell.RCFile(shellType)
+	if err != nil {
+		return err
+	}
+
+	var changed bool
+	if reverse {
+		changed, err = shell.RemoveBlock(rcFile)
+	} else {
+		var block string
+		block, err = shell.InitBlock(shellType, executable)
+		if err != nil {
+			return err
+		}
+		changed, err = shell.InstallBlock(rcFile, block)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", rcFile, err)
+	}
+
+	if !changed {
+		fmt.Printf("No action taken: %s is already up to date\n", rcFile)
+		return nil
+	}
+	fmt.Printf("Modified %s\n", rcFile)
+	if !reverse {
+		fmt.Println("Restart your shell for the changes to take effect")
+	}
+	return nil
+}
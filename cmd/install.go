@@ -3,12 +3,8 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
-	"runtime"
 
 	"github.com/urfave/cli/v3"
-	"github.com/uda/uda/internal/config"
 	"github.com/uda/uda/internal/env"
 	"github.com/uda/uda/internal/uv"
 )
@@ -29,33 +25,14 @@ var installCmd = &cli.Command{
 		},
 	},
 	Action: func(ctx context.Context, cmd *cli.Command) error {
-		envName := cmd.String("env")
 		reqFile := cmd.String("requirements")
 
-		// Try to get env from VIRTUAL_ENV if not specified
-		if envName == "" {
-			virtualEnv := os.Getenv("VIRTUAL_ENV")
-			if virtualEnv != "" {
-				// VIRTUAL_ENV is a path, extract env name
-				envName = filepath.Base(virtualEnv)
-			}
+		envName, err := resolveEnv(cmd.String("env"))
+		if err != nil {
+			return err
 		}
 
-		if envName == "" {
-			return fmt.Errorf("environment not specified. Use --env or set VIRTUAL_ENV")
-		}
-
-		if !env.Exists(envName) {
-			return fmt.Errorf("environment %s does not exist", envName)
-		}
-
-		envPath := config.EnvPath(envName)
-		var python string
-		if runtime.GOOS == "windows" {
-			python = filepath.Join(envPath, "Scripts", "python.exe")
-		} else {
-			python = filepath.Join(envPath, "bin", "python")
-		}
+		python := uv.GetPythonPath(envName)
 
 		// Build uv pip install command
 		args := []string{"pip", "install"}
@@ -70,6 +47,27 @@ var installCmd = &cli.Command{
 			return fmt.Errorf("no packages specified")
 		}
 
-		return uv.RunUvWithPython(python, args...)
+		if err := uv.RunUvWithPython(ctx, python, args...); err != nil {
+			return err
+		}
+
+		if reqFile != "" {
+			return recordInstall(envName, nil, []string{reqFile})
+		}
+		return recordInstall(envName, cmd.Args().Slice(), nil)
 	},
 }
+
+// recordInstall records what was requested so the env can be rebuilt later.
+// Local projects and files are recorded by absolute path, so a rebuild from
+// another directory installs the same ones.
+func recordInstall(envName string, packages []string, reqFiles []string) error {
+	meta, err := env.LoadMeta(envName)
+	if err != nil {
+		return err
+	}
+	packages, _ = absInstallArgs(packages)
+	meta.AddRequirements(reqFiles...)
+	meta.AddPackages(packages...)
+	return env.SaveMeta(envName, meta)
+}
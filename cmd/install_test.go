@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/uda/uda/internal/config"
+	"github.com/uda/uda/internal/env"
+)
+
+func TestRecordInstallMakesLocalPathsAbsolute(t *testing.T) {
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+	if err := os.MkdirAll(config.EnvPath("proj"), 0755); err != nil {
+		t.Fatalf("prepare env path: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0755); err != nil {
+		t.Fatalf("prepare project: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pkg", "pyproject.toml"), []byte("[project]\nname = \"My_Pkg\"\n"), 0644); err != nil {
+		t.Fatalf("write pyproject: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "dist"), 0755); err != nil {
+		t.Fatalf("prepare dist: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dist", "foo-1.0-py3-none-any.whl"), nil, 0644); err != nil {
+		t.Fatalf("write wheel: %v", err)
+	}
+	oldDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer os.Chdir(oldDir)
+	// t.TempDir may be behind a symlink, as on macOS
+	dir, _ = os.Getwd()
+
+	if err := recordInstall("proj", []string{"./pkg[test]", "dist/foo-1.0-py3-none-any.whl", "rich"}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	meta, err := env.LoadMeta("proj")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "pkg") + "[test]", filepath.Join(dir, "dist", "foo-1.0-py3-none-any.whl"), "rich"}
+	if !reflect.DeepEqual(meta.Packages, expected) {
+		t.Fatalf("unexpected packages: %q", meta.Packages)
+	}
+
+	meta.RemovePackages("my-pkg", "foo")
+	if !reflect.DeepEqual(meta.Packages, []string{"rich"}) {
+		t.Fatalf("expected local packages to be removable by name, got %q", meta.Packages)
+	}
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/env"
+)
+
+var matrixCmd = &cli.Command{
+	Name:         "matrix",
+	Usage:        "Run a command across Python versions or environments in parallel",
+	ArgsUsage:    "[--] command [args...]",
+	StopOnNthArg: &commandArg,
+	// Package specs such as "numpy>=1,<2" contain commas
+	DisableSliceFlagSeparator: true,
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "python",
+			Usage: "Python versions to run on, comma separated (repeatable)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "envs",
+			Usage: "Existing environments to run in, comma separated (repeatable)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "install",
+			Usage: "pip install arguments for the --python envs, e.g. \".[test]\" (repeatable)",
+		},
+		&cli.IntFlag{
+			Name:    "jobs",
+			Aliases: []string{"j"},
+			Usage:   "Number of targets to run at once",
+			Value:   runtime.NumCPU(),
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		args := commandArgs(cmd)
+		if len(args) == 0 {
+			return fmt.Errorf("a command is required, e.g. uda matrix --python 3.11,3.12 -- pytest")
+		}
+
+		var targets []matrixTarget
+		for _, python := range splitList(cmd.StringSlice("python")) {
+			targets = append(targets, matrixTarget{label: "py" + python, python: python})
+		}
+		for _, name := range splitList(cmd.StringSlice("envs")) {
+			if !env.Exists(name) {
+				return fmt.Errorf("environment %s does not exist", name)
+			}
+			targets = append(targets, matrixTarget{label: name, envName: name})
+		}
+		if len(targets) == 0 {
+			return fmt.Errorf("--python or --envs is required")
+		}
+
+		var install []string
+		for _, value := range cmd.StringSlice("install") {
+			install = append(install, strings.Fields(value)...)
+		}
+		specs, local := absInstallArgs(install)
+
+		jobs := cmd.Int("jobs")
+		if jobs < 1 {
+			jobs = 1
+		}
+
+		width := 0
+		for _, t := range targets {
+			width = max(width, len(t.label))
+		}
+
+		var mu sync.Mutex
+		results := make([]matrixResult, len(targets))
+		slots := make(chan struct{}, jobs)
+		var wg sync.WaitGroup
+		for i, t := range targets {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+
+				prefix := fmt.Sprintf("[%-*s] ", width, t.label)
+				stdout := &prefixWriter{mu: &mu, out: os.Stdout, prefix: prefix}
+				stderr := &prefixWriter{mu: &mu, out: os.Stderr, prefix: prefix}
+				results[i] = t.run(ctx, args, specs, local, stdout, stderr)
+				stdout.Flush()
+				stderr.Flush()
+			}()
+		}
+		wg.Wait()
+
+		return printMatrixSummary(targets, results)
+	},
+}
+
+// matrixTarget is a Python version, run in a cached ephemeral env, or an
+// existing environment
+type matrixTarget struct {
+	label   string
+	python  string
+	envName string
+}
+
+type matrixResult struct {
+	python   string
+	code     int
+	err      error
+	duration time.Duration
+}
+
+// run provisions the target's env and runs args in it. specs are installed
+// into ephemeral envs; with local, they are installed again when the env is
+// reused so changes to local projects and requirements files are picked up.
+func (t matrixTarget) run(ctx context.Context, args []string, specs []string, local bool, stdout, stderr io.Writer) (result matrixResult) {
+	start := time.Now()
+	defer func() { result.duration = time.Since(start) }()
+	result.code = -1
+
+	var environ []string
+	if t.envName != "" {
+		var err error
+		environ, err = env.Environ(t.envName, os.Environ(), nil)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		result.python, _ = env.PythonVersion(t.envName)
+	} else {
+		path, created, err := env.Ephemeral(ctx, t.python, specs, stdout, stderr)
+		if err == nil && !created && local {
+			err = env.InstallInto(ctx, path, append(reinstallArgs(specs), specs...), stdout, stderr)
+		}
+		if err != nil {
+			result.err = err
+			return result
+		}
+		environ = env.EnvironAt(path, os.Environ(), nil)
+		result.python, _ = env.PythonVersionAt(path)
+	}
+
+	child, err := env.CommandContext(ctx, environ, args[0], args[1:]...)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	child.Stdout = stdout
+	child.Stderr = stderr
+
+	err = child.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		result.code = 0
+	case errors.As(err, &exitErr) && ctx.Err() == nil:
+		result.code = exitErr.ExitCode()
+	default:
+		result.err = err
+	}
+	return result
+}
+
+// printMatrixSummary prints one line per target and fails unless every
+// target passed
+func printMatrixSummary(targets []matrixTarget, results []matrixResult) error {
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TARGET\tPYTHON\tRESULT\tTIME")
+	failed := 0
+	for i, t := range targets {
+		r := results[i]
+		status := "pass"
+		switch {
+		case r.err != nil:
+			status = "error: " + r.err.Error()
+			failed++
+		case r.code != 0:
+			status = fmt.Sprintf("fail (exit %d)", r.code)
+			failed++
+		}
+		python := r.python
+		if python == "" {
+			python = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.label, python, status, r.duration.Round(100*time.Millisecond))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d targets failed", failed, len(targets))
+	}
+	return nil
+}
+
+// pathFlags are pip install flags whose value is a local path
+var pathFlags = map[string]bool{"-r": true, "--requirement": true, "-c": true, "--constraint": true, "-e": true, "--editable": true}
+
+// absInstallArgs makes local paths in pip install arguments absolute, so
+// the cache key of an ephemeral env tells projects apart, and reports
+// whether there were any
+func absInstallArgs(args []string) ([]string, bool) {
+	var specs []string
+	local := false
+	for i, arg := range args {
+		path, extras, _ := strings.Cut(arg, "[")
+		looksLocal := i > 0 && pathFlags[args[i-1]] ||
+			strings.HasPrefix(path, ".") || strings.ContainsAny(path, `/\`) ||
+			strings.HasSuffix(path, ".whl") || strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".zip")
+		if !strings.HasPrefix(arg, "-") && looksLocal {
+			if _, err := os.Stat(path); err == nil {
+				if abs, err := filepath.Abs(path); err == nil {
+					local = true
+					if extras != "" {
+						abs += "[" + extras
+					}
+					specs = append(specs, abs)
+					continue
+				}
+			}
+		}
+		specs = append(specs, arg)
+	}
+	return specs, local
+}
+
+// reinstallArgs scopes a reinstall to the local projects and archives among
+// absolute specs, so reusing a cached env rebuilds only those. Editables and
+// requirements files need none: editables follow their source and uv pip
+// install picks up changed requirements by itself.
+func reinstallArgs(specs []string) []string {
+	var args []string
+	for i, spec := range specs {
+		if strings.HasPrefix(spec, "-") || i > 0 && pathFlags[specs[i-1]] {
+			continue
+		}
+		path, _, _ := strings.Cut(spec, "[")
+		if !filepath.IsAbs(path) {
+			continue
+		}
+		if name := env.LocalPackageName(path); name != "" {
+			args = append(args, "--reinstall-package", name)
+		}
+	}
+	return args
+}
+
+// splitList flattens comma separated flag values
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// prefixWriter writes complete lines to out with a prefix. Writers sharing
+// mu never interleave within a line.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		fmt.Fprintf(w.out, "%s%s\n", w.prefix, w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes a trailing line without a newline
+func (w *prefixWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		fmt.Fprintf(w.out, "%s%s\n", w.prefix, w.buf)
+		w.buf = nil
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/env"
+	"github.com/uda/uda/internal/envdiff"
+	"github.com/uda/uda/internal/uv"
+)
+
+// pipCmd takes pip's command line so the shell wrappers can route pip and
+// pip3 to the active env. Installs and uninstalls go through uv and are
+// recorded in the env's metadata like `uda install`.
+var pipCmd = &cli.Command{
+	Name:            "pip",
+	Usage:           "Run pip install/uninstall/list/freeze/show against the active environment",
+	ArgsUsage:       "<install|uninstall|list|freeze|show> [args...]",
+	SkipFlagParsing: true,
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		args := cmd.Args().Slice()
+		if len(args) == 0 {
+			return fmt.Errorf("pip subcommand is required (install, uninstall, list, freeze, show)")
+		}
+
+		envName, err := resolveEnv("")
+		if err != nil {
+			return err
+		}
+		python := uv.GetPythonPath(envName)
+
+		sub, args := args[0], args[1:]
+		switch sub {
+		case "install":
+			packages, reqFiles := pipInstallTargets(args)
+			if err := uv.RunUvWithPython(ctx, python, append([]string{"pip", "install"}, args...)...); err != nil {
+				return err
+			}
+			return recordInstall(envName, packages, reqFiles)
+		case "uninstall":
+			packages, reqFiles, uvArgs := pipUninstallTargets(args)
+			if err := uv.RunUvWithPython(ctx, python, append([]string{"pip", "uninstall"}, uvArgs...)...); err != nil {
+				return err
+			}
+			// Packages uninstalled through a requirements file are forgotten too
+			for _, file := range reqFiles {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				for name := range envdiff.ParseRequirements(data) {
+					packages = append(packages, name)
+				}
+			}
+			meta, err := env.LoadMeta(envName)
+			if err != nil {
+				return err
+			}
+			meta.RemovePackages(packages...)
+			meta.RemoveRequirements(reqFiles...)
+			return env.SaveMeta(envName, meta)
+		case "list", "freeze", "show":
+			return uv.RunUvWithPython(ctx, python, append([]string{"pip", sub}, args...)...)
+		default:
+			return fmt.Errorf("pip %s is not handled by uda, run it with `command pip %s`", sub, sub)
+		}
+	},
+}
+
+// pipInstallValueFlags are pip install options whose value is a separate argument
+var pipInstallValueFlags = map[string]bool{
+	"-c": true, "--constraint": true,
+	"-i": true, "--index-url": true,
+	"--extra-index-url": true,
+	"-f": true, "--find-links": true,
+	"-t": true, "--target": true,
+	"--prefix": true, "--python-version": true, "--platform": true,
+	"--no-binary": true, "--only-binary": true,
+}
+
+// pipInstallTargets splits pip install arguments into the package specs and
+// requirements files to record; editable targets are recorded as "-e <path>"
+// and other options are only passed on to uv
+func pipInstallTargets(args []string) (packages []string, reqFiles []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-r" || arg == "--requirement":
+			if i+1 < len(args) {
+				i++
+				reqFiles = append(reqFiles, args[i])
+			}
+		case strings.HasPrefix(arg, "--requirement="):
+			reqFiles = append(reqFiles, strings.TrimPrefix(arg, "--requirement="))
+		case strings.HasPrefix(arg, "-r") && !strings.HasPrefix(arg, "--"):
+			reqFiles = append(reqFiles, strings.TrimPrefix(arg, "-r"))
+		case arg == "-e" || arg == "--editable":
+			if i+1 < len(args) {
+				i++
+				packages = append(packages, editableSpec(args[i]))
+			}
+		case strings.HasPrefix(arg, "--editable="):
+			packages = append(packages, editableSpec(strings.TrimPrefix(arg, "--editable=")))
+		case strings.HasPrefix(arg, "-e") && !strings.HasPrefix(arg, "--"):
+			packages = append(packages, editableSpec(strings.TrimPrefix(arg, "-e")))
+		case pipInstallValueFlags[arg]:
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			packages = append(packages, arg)
+		}
+	}
+	return packages, reqFiles
+}
+
+// pipUninstallTargets splits pip uninstall arguments into the packages and
+// requirements files to forget, and the arguments to pass on to uv
+func pipUninstallTargets(args []string) (packages []string, reqFiles []string, uvArgs []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		// uv never prompts, so pip's confirmation flag has no equivalent
+		if arg == "-y" || arg == "--yes" {
+			continue
+		}
+		uvArgs = append(uvArgs, arg)
+		switch {
+		case arg == "-r" || arg == "--requirement":
+			if i+1 < len(args) {
+				i++
+				uvArgs = append(uvArgs, args[i])
+				reqFiles = append(reqFiles, args[i])
+			}
+		case strings.HasPrefix(arg, "--requirement="):
+			reqFiles = append(reqFiles, strings.TrimPrefix(arg, "--requirement="))
+		case strings.HasPrefix(arg, "-r") && !strings.HasPrefix(arg, "--"):
+			reqFiles = append(reqFiles, strings.TrimPrefix(arg, "-r"))
+		case strings.HasPrefix(arg, "-"):
+		default:
+			packages = append(packages, arg)
+		}
+	}
+	return packages, reqFiles, uvArgs
+}
+
+// editableSpec records an editable target, making a local path absolute so
+// it can be installed again from anywhere
+func editableSpec(target string) string {
+	path, extras, hasExtras := strings.Cut(target, "[")
+	if _, err := os.Stat(path); err == nil {
+		if abs, err := filepath.Abs(path); err == nil {
+			target = abs
+			if hasExtras {
+				target += "[" + extras
+			}
+		}
+	}
+	return env.EditablePrefix + target
+}
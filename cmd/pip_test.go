@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/uda/uda/internal/env"
+)
+
+func TestPipInstallTargetsRecordsEditables(t *testing.T) {
+	dir := t.TempDir()
+	packages, reqFiles := pipInstallTargets([]string{
+		"-e", dir, "--editable=" + dir + "[test]", "-r", "req.txt", "-i", "https://index", "rich",
+		"-e", "git+https://example.com/tool.git#egg=tool",
+	})
+
+	expected := []string{
+		"-e " + dir,
+		"-e " + dir + "[test]",
+		"rich",
+		"-e git+https://example.com/tool.git#egg=tool",
+	}
+	if !reflect.DeepEqual(packages, expected) || !reflect.DeepEqual(reqFiles, []string{"req.txt"}) {
+		t.Fatalf("unexpected targets: %q, %q", packages, reqFiles)
+	}
+	if name := env.RequirementName(packages[0]); name != env.NormalizeName(filepath.Base(dir)) {
+		t.Fatalf("unexpected editable name: %q", name)
+	}
+	if name := env.RequirementName(packages[3]); name != "tool" {
+		t.Fatalf("unexpected editable name: %q", name)
+	}
+	if args := env.PackageArgs(packages[1]); !reflect.DeepEqual(args, []string{"-e", dir + "[test]"}) {
+		t.Fatalf("unexpected install args: %q", args)
+	}
+}
+
+func TestPipUninstallTargetsReadsRequirementFiles(t *testing.T) {
+	packages, reqFiles, uvArgs := pipUninstallTargets([]string{"-y", "rich", "-r", "a.txt", "--requirement=b.txt", "-rc.txt"})
+	if !reflect.DeepEqual(packages, []string{"rich"}) || !reflect.DeepEqual(reqFiles, []string{"a.txt", "b.txt", "c.txt"}) {
+		t.Fatalf("unexpected targets: %q, %q", packages, reqFiles)
+	}
+	if !reflect.DeepEqual(uvArgs, []string{"rich", "-r", "a.txt", "--requirement=b.txt", "-rc.txt"}) {
+		t.Fatalf("unexpected uv arguments: %q", uvArgs)
+	}
+}
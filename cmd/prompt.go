@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/shell"
+)
+
+var promptCmd = &cli.Command{
+	Name:  "prompt",
+	Usage: "Print the prompt prefix of the active environment for prompt frameworks",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Format instead of env_prompt, e.g. \"{name}|py{python_version}\"",
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		fmt.Print(shell.CurrentModifier(cmd.String("format")))
+		return nil
+	},
+}
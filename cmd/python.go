@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/env"
+	"github.com/uda/uda/internal/uv"
+)
+
+var pythonCmd = &cli.Command{
+	Name:  "python",
+	Usage: "Manage Python interpreters",
+	Commands: []*cli.Command{
+		{
+			Name:      "list",
+			Aliases:   []string{"ls"},
+			Usage:     "List installed and available Python versions",
+			ArgsUsage: "[version]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "only-installed",
+					Usage: "Only show installed interpreters",
+				},
+			},
+			Action: pythonList,
+		},
+		{
+			Name:      "install",
+			Usage:     "Install Python versions",
+			ArgsUsage: "<version>...",
+			Action:    pythonInstall,
+		},
+		{
+			Name:      "uninstall",
+			Usage:     "Uninstall a Python version",
+			ArgsUsage: "<version>",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "Uninstall even if environments still use the interpreter",
+				},
+			},
+			Action: pythonUninstall,
+		},
+		{
+			Name:      "which",
+			Usage:     "Show the interpreter path for a Python version",
+			ArgsUsage: "<version>",
+			Action:    pythonWhich,
+		},
+	},
+}
+
+var pythonList = func(ctx context.Context, cmd *cli.Command) error {
+	pythons, err := uv.ListPythons(ctx, cmd.Args().First(), cmd.Bool("only-installed"))
+	if err != nil {
+		return err
+	}
+
+	if len(pythons) == 0 {
+		fmt.Println("No Python versions found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, p := range pythons {
+		if !p.Installed() {
+			fmt.Fprintf(w, "%s\t<download available>\t\n", p.Key)
+			continue
+		}
+
+		users, err := env.UsersOf(p.Path, p.Version)
+		if err != nil {
+			return err
+		}
+		used := ""
+		if len(users) > 0 {
+			used = "used by: " + strings.Join(users, ", ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Key, p.Path, used)
+	}
+	return w.Flush()
+}
+
+var pythonInstall = func(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() == 0 {
+		return fmt.Errorf("Python version is required")
+	}
+
+	for _, version := range cmd.Args().Slice() {
+		fmt.Printf("Installing Python %s...\n", version)
+		if err := uv.InstallPython(ctx, version); err != nil {
+			return fmt.Errorf("failed to install Python %s: %w", version, err)
+		}
+	}
+	return nil
+}
+
+var pythonUninstall = func(ctx context.Context, cmd *cli.Command) error {
+	version := cmd.Args().First()
+	if version == "" {
+		return fmt.Errorf("Python version is required")
+	}
+
+	if !cmd.Bool("force") {
+		users, err := managedPythonUsers(ctx, version)
+		if err != nil {
+			return err
+		}
+		if len(users) > 0 {
+			return fmt.Errorf("Python %s is still used by: %s (use --force to uninstall anyway)", version, strings.Join(users, ", "))
+		}
+	}
+
+	fmt.Printf("Uninstalling Python %s...\n", version)
+	return uv.UninstallPython(ctx, version)
+}
+
+// managedPythonUsers returns the environments built on the uv-managed
+// interpreters matching version. Envs on a system interpreter of the same
+// version do not count, since uv python uninstall leaves those alone.
+func managedPythonUsers(ctx context.Context, version string) ([]string, error) {
+	pythons, err := uv.ListManagedPythons(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []string
+	for _, p := range pythons {
+		u, err := env.UsersOf(p.Path, p.Version)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u...)
+	}
+	return users, nil
+}
+
+var pythonWhich = func(ctx context.Context, cmd *cli.Command) error {
+	version := cmd.Args().First()
+	if version == "" {
+		return fmt.Errorf("Python version is required")
+	}
+
+	path, err := uv.FindPython(ctx, version)
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+	return nil
+}
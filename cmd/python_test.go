@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/uda/uda/internal/config"
+)
+
+func TestManagedPythonUsersIgnoresSystemInterpreters(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake uv is a shell script")
+	}
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+
+	dir := t.TempDir()
+	managedBin := filepath.Join(dir, "managed", "cpython-3.11.9", "bin")
+	systemBin := filepath.Join(dir, "usr", "bin")
+	for name, home := range map[string]string{"on-managed": managedBin, "on-system": systemBin} {
+		if err := os.MkdirAll(config.EnvPath(name), 0755); err != nil {
+			t.Fatalf("prepare env path: %v", err)
+		}
+		cfg := fmt.Sprintf("home = %s\nversion_info = 3.11.9\n", home)
+		if err := os.WriteFile(filepath.Join(config.EnvPath(name), "pyvenv.cfg"), []byte(cfg), 0644); err != nil {
+			t.Fatalf("write pyvenv.cfg: %v", err)
+		}
+	}
+
+	// The fake uv lists the system interpreter unless asked for managed ones only
+	managed := fmt.Sprintf(`{"key":"cpython-3.11.9","version":"3.11.9","path":"%s/python3.11"}`, managedBin)
+	system := fmt.Sprintf(`{"key":"cpython-3.11.9","version":"3.11.9","path":"%s/python3.11"}`, systemBin)
+	script := fmt.Sprintf(`#!/bin/sh
+case "$*" in
+*only-managed*) echo '[%s]' ;;
+*) echo '[%s,%s]' ;;
+esac
+`, managed, managed, system)
+	if err := os.MkdirAll(config.HomeDir, 0755); err != nil {
+		t.Fatalf("prepare home: %v", err)
+	}
+	if err := os.WriteFile(config.UvPath(), []byte(script), 0755); err != nil {
+		t.Fatalf("write fake uv: %v", err)
+	}
+
+	users, err := managedPythonUsers(context.Background(), "3.11")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(users, []string{"on-managed"}) {
+		t.Fatalf("expected only the env on the managed interpreter, got %v", users)
+	}
+}
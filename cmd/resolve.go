@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/uda/uda/internal/env"
+)
+
+// resolveEnv picks the target environment: an explicit name, then the
+// active VIRTUAL_ENV, then an env bound to the current directory.
+func resolveEnv(envName string) (string, error) {
+	// Try to get env from VIRTUAL_ENV if not specified
+	if envName == "" {
+		virtualEnv := os.Getenv("VIRTUAL_ENV")
+		if virtualEnv != "" {
+			// VIRTUAL_ENV is a path, extract env name
+			envName = filepath.Base(virtualEnv)
+		}
+	}
+
+	if envName == "" {
+		if cwd, err := os.Getwd(); err == nil {
+			envName = env.Bound(cwd)
+		}
+	}
+
+	if envName == "" {
+		return "", fmt.Errorf("environment not specified. Use --env or set VIRTUAL_ENV")
+	}
+
+	if !env.Exists(envName) {
+		return "", fmt.Errorf("environment %s does not exist", envName)
+	}
+	return envName, nil
+}
@@ -2,7 +2,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/urfave/cli/v3"
 	"github.com/uda/uda/internal/config"
@@ -16,22 +20,72 @@ func Execute() error {
 		return err
 	}
 
+	// Ctrl-C and SIGTERM cancel the context, which stops uv subprocesses
+	// and downloads
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var runCtx context.Context
+	cancelTimeout := func() {}
+	defer func() { cancelTimeout() }()
+
 	app := &cli.Command{
 		Name:    "uda",
 		Usage:   "Python environment manager combining Conda and UV",
 		Version: version,
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "Give up on network and uv steps after this long (e.g. 90s, 10m)",
+			},
+		},
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			runCtx = ctx
+			if timeout := cmd.Duration("timeout"); timeout > 0 {
+				runCtx, cancelTimeout = context.WithTimeout(ctx, timeout)
+			}
+			return runCtx, nil
+		},
 		Commands: []*cli.Command{
 			createCmd,
 			listCmd,
 			removeCmd,
 			activateCmd,
 			deactivateCmd,
+			shellCmd,
+			promptCmd,
 			installCmd,
+			pipCmd,
 			runCmd,
+			matrixCmd,
+			scriptCmd,
+			diffCmd,
+			envCmd,
+			pythonCmd,
+			upgradePythonCmd,
 			selfCmd,
+			cacheCmd,
 			initCmd,
+			completeCmd,
 		},
+		ExitErrHandler: handleExit,
 	}
 
-	return app.Run(context.Background(), os.Args)
+	err := app.Run(ctx, os.Args)
+	switch {
+	case err == nil:
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("interrupted")
+	case runCtx != nil && errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("timed out after %s: %w", app.Duration("timeout"), err)
+	}
+	return err
+}
+
+// handleExit exits quietly for errors that only carry a child's exit code
+func handleExit(ctx context.Context, cmd *cli.Command, err error) {
+	var exitErr cli.ExitCoder
+	if errors.As(err, &exitErr) && exitErr.Error() == "" {
+		os.Exit(exitErr.ExitCode())
+	}
+	cli.HandleExitCoder(err)
 }
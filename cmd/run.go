@@ -2,62 +2,183 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
-	"runtime"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"syscall"
 
 	"github.com/urfave/cli/v3"
-	"github.com/uda/uda/internal/config"
+	"github.com/uda/uda/internal/dotenv"
 	"github.com/uda/uda/internal/env"
 	"github.com/uda/uda/internal/uv"
 )
 
+// commandArg stops flag parsing at the command name, so the command's own
+// flags are passed through untouched
+var commandArg = 1
+
 var runCmd = &cli.Command{
-	Name:  "run",
-	Usage: "Run a command in an environment",
+	Name:         "run",
+	Usage:        "Run a command in an environment",
+	ArgsUsage:    "[command [args...]]",
+	StopOnNthArg: &commandArg,
+	// Package specs such as "numpy>=1,<2" contain commas
+	DisableSliceFlagSeparator: true,
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:  "env",
 			Usage: "Environment name",
 		},
+		&cli.StringSliceFlag{
+			Name:  "with",
+			Usage: "Run in a cached ephemeral env with this package spec installed (repeatable)",
+		},
+		&cli.StringFlag{
+			Name:  "python",
+			Usage: "Python version of the ephemeral env",
+		},
+		&cli.StringSliceFlag{
+			Name:  "env-file",
+			Usage: "Load variables from a dotenv file (repeatable, later files win)",
+		},
+		&cli.StringFlag{
+			Name:  "cwd",
+			Usage: "Run the command in this directory",
+		},
+		&cli.BoolFlag{
+			Name:  "clear-env",
+			Usage: "Start from a minimal environment instead of the current one",
+		},
 	},
 	Action: func(ctx context.Context, cmd *cli.Command) error {
-		envName := cmd.String("env")
+		base := os.Environ()
+		if cmd.Bool("clear-env") {
+			base = env.MinimalEnviron()
+		}
+		overrides, err := loadEnvFiles(cmd.StringSlice("env-file"))
+		if err != nil {
+			return err
+		}
 
-		// Try to get env from VIRTUAL_ENV if not specified
-		if envName == "" {
-			virtualEnv := os.Getenv("VIRTUAL_ENV")
-			if virtualEnv != "" {
-				envName = filepath.Base(virtualEnv)
+		var python string
+		var environ []string
+		if cmd.IsSet("with") || cmd.IsSet("python") {
+			if cmd.IsSet("env") {
+				return fmt.Errorf("--with and --python cannot be combined with --env")
+			}
+			specs, local := absInstallArgs(cmd.StringSlice("with"))
+			path, created, err := env.Ephemeral(ctx, cmd.String("python"), specs, os.Stderr, os.Stderr)
+			if err == nil && !created && local {
+				err = env.InstallInto(ctx, path, append(reinstallArgs(specs), specs...), os.Stderr, os.Stderr)
+			}
+			if err != nil {
+				return err
+			}
+			python = uv.VenvPython(path)
+			environ = env.EnvironAt(path, base, overrides)
+		} else {
+			envName, err := resolveEnv(cmd.String("env"))
+			if err != nil {
+				return err
+			}
+			python = uv.GetPythonPath(envName)
+			environ, err = env.Environ(envName, base, overrides)
+			if err != nil {
+				return err
 			}
 		}
 
-		if envName == "" {
-			return fmt.Errorf("environment not specified. Use --env or set VIRTUAL_ENV")
+		// Default to the python REPL
+		args := commandArgs(cmd)
+		if len(args) == 0 {
+			args = []string{python}
 		}
 
-		if !env.Exists(envName) {
-			return fmt.Errorf("environment %s does not exist", envName)
+		child, err := env.Command(environ, args[0], args[1:]...)
+		if err != nil {
+			return err
 		}
+		if dir := cmd.String("cwd"); dir != "" {
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				return fmt.Errorf("--cwd %s is not a directory", dir)
+			}
+			child.Dir = dir
+		}
+		return runChild(child)
+	},
+}
 
-		envPath := config.EnvPath(envName)
-		var python string
-		if runtime.GOOS == "windows" {
-			python = filepath.Join(envPath, "Scripts", "python.exe")
-		} else {
-			python = filepath.Join(envPath, "bin", "python")
+// commandArgs returns the command line to run. urfave/cli puts an empty
+// default command name in front when the command's name equals a flag that
+// was set, as in "run --env x env", so that is dropped.
+func commandArgs(cmd *cli.Command) []string {
+	args := cmd.Args().Slice()
+	if len(args) > 1 && args[0] == "" && slices.Contains(cmd.FlagNames(), args[1]) {
+		return args[1:]
+	}
+	return args
+}
+
+// loadEnvFiles reads dotenv files in order, later files winning
+func loadEnvFiles(files []string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, file := range files {
+		fileVars, err := dotenv.ParseFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env file: %w", err)
+		}
+		for key, value := range fileVars {
+			vars[key] = value
 		}
+	}
+	return vars, nil
+}
 
-		// Use uv run with the specific python
-		args := []string{}
-		if len(cmd.Args().Slice()) > 0 {
-			args = append(args, cmd.Args().Slice()...)
-		} else {
-			// Default to python REPL
-			args = append(args, python)
+// runChild runs a command in the foreground, forwards termination signals
+// to it and exits with its exit status. SIGINT and SIGQUIT from a terminal
+// already reach the child through its process group, so they are only
+// forwarded when stdin is not a terminal.
+func runChild(child *exec.Cmd) error {
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	if err := child.Start(); err != nil {
+		return err
+	}
+
+	fromTerminal := false
+	if info, err := os.Stdin.Stat(); err == nil {
+		fromTerminal = info.Mode()&os.ModeCharDevice != 0
+	}
+	go func() {
+		for sig := range signals {
+			if fromTerminal && (sig == os.Interrupt || sig == syscall.SIGQUIT) {
+				continue
+			}
+			child.Process.Signal(sig)
 		}
+	}()
 
-		return uv.RunUvWithPython(python, append([]string{"run"}, args...)...)
-	},
+	err := child.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		// Like a shell, report death by signal n as 128+n
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			code = 128 + int(status.Signal())
+		}
+		return cli.Exit("", code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", child.Args[0], err)
+	}
+	return nil
 }
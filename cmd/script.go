@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/env"
+	"github.com/uda/uda/internal/script"
+	"github.com/uda/uda/internal/uv"
+)
+
+var scriptCmd = &cli.Command{
+	Name:  "script",
+	Usage: "Run single-file scripts with PEP 723 inline metadata",
+	Commands: []*cli.Command{
+		{
+			Name:      "run",
+			Usage:     "Run a script in a cached env built from its metadata",
+			ArgsUsage: "<file.py> [args...]",
+			// Everything after the script belongs to the script, which also
+			// makes "#!/usr/bin/env -S uda script run" work
+			StopOnNthArg: &commandArg,
+			Action:       scriptRun,
+		},
+		{
+			Name:      "lock",
+			Usage:     "Pin a script's dependencies in <file.py>.lock",
+			ArgsUsage: "<file.py>",
+			Action:    scriptLock,
+		},
+	},
+}
+
+var scriptRun = func(ctx context.Context, cmd *cli.Command) error {
+	args := commandArgs(cmd)
+	if len(args) == 0 {
+		return fmt.Errorf("script file is required")
+	}
+	path := args[0]
+
+	meta, err := script.Load(path)
+	if err != nil {
+		return err
+	}
+
+	specs := meta.Dependencies
+	lock, err := script.LoadLock(path)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		if lock.Hash == meta.Hash() {
+			specs = lock.Requirements
+		} else {
+			fmt.Fprintf(os.Stderr, "uda: %s is out of date, using the script's dependencies; run 'uda script lock %s' to update it\n", script.LockPath(path), path)
+		}
+	}
+
+	envPath, _, err := env.Ephemeral(ctx, meta.RequiresPython, specs, os.Stderr, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	environ := env.EnvironAt(envPath, os.Environ(), nil)
+	child, err := env.Command(environ, uv.VenvPython(envPath), append([]string{path}, args[1:]...)...)
+	if err != nil {
+		return err
+	}
+	return runChild(child)
+}
+
+var scriptLock = func(ctx context.Context, cmd *cli.Command) error {
+	path := cmd.Args().First()
+	if path == "" {
+		return fmt.Errorf("script file is required")
+	}
+
+	meta, err := script.Load(path)
+	if err != nil {
+		return err
+	}
+
+	// Compile against the interpreter script run would use
+	envPath, _, err := env.Ephemeral(ctx, meta.RequiresPython, nil, os.Stderr, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "uda-lock-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "requirements.in")
+	if err := os.WriteFile(input, []byte(strings.Join(meta.Dependencies, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+
+	out, err := uv.OutputUvWithPython(ctx, uv.VenvPython(envPath), "pip", "compile", input, "--no-header", "--no-annotate", "--quiet")
+	if err != nil {
+		return fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	lockPath := script.LockPath(path)
+	if err := os.WriteFile(lockPath, []byte(script.FormatLock(meta, string(out))), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Locked dependencies of %s in %s\n", path, lockPath)
+	return nil
+}
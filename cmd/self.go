@@ -22,5 +22,5 @@ var selfCmd = &cli.Command{
 
 var selfInstall = func(ctx context.Context, cmd *cli.Command) error {
 	fmt.Println("Installing uv...")
-	return uv.Install()
+	return uv.Install(ctx)
 }
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/env"
+	"github.com/uda/uda/internal/shell"
+)
+
+var shellCmd = &cli.Command{
+	Name:      "shell",
+	Usage:     "Start $SHELL with an environment activated; exit to leave it",
+	ArgsUsage: "<name>",
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		envName := cmd.Args().First()
+		if envName == "" {
+			return fmt.Errorf("environment name is required")
+		}
+
+		if !env.Exists(envName) {
+			return fmt.Errorf("environment %s does not exist", envName)
+		}
+
+		shellPath := os.Getenv("SHELL")
+		if shellPath == "" {
+			shellPath = "/bin/sh"
+		}
+
+		child, cleanup, err := shell.Subshell(shellPath, envName)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		fmt.Fprintf(os.Stderr, "Starting %s with %s activated, exit to leave it\n", shellPath, envName)
+		if err := child.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				return cli.Exit("", exitErr.ExitCode())
+			}
+			return fmt.Errorf("failed to start %s: %w", shellPath, err)
+		}
+		return nil
+	},
+}
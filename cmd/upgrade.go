@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"github.com/uda/uda/internal/env"
+	"github.com/uda/uda/internal/envdiff"
+	"github.com/uda/uda/internal/uv"
+)
+
+var upgradePythonCmd = &cli.Command{
+	Name:      "upgrade-python",
+	Usage:     "Rebuild an environment on a different Python version",
+	ArgsUsage: "<env> <version>",
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		name := cmd.Args().Get(0)
+		version := cmd.Args().Get(1)
+		if name == "" || version == "" {
+			return fmt.Errorf("environment name and Python version are required")
+		}
+
+		if !env.Exists(name) {
+			return fmt.Errorf("environment %s does not exist", name)
+		}
+
+		meta, err := env.LoadMeta(name)
+		if err != nil {
+			return err
+		}
+
+		// Snapshot what the env was asked for, not what it resolved to,
+		// so packages can move to releases that support the new Python
+		packages := meta.Packages
+		if len(packages) == 0 && len(meta.Requirements) == 0 {
+			// These stay out of meta.Packages, which only lists what was asked for
+			fmt.Printf("No requested packages recorded for %s, reinstalling installed packages by name\n", name)
+			fmt.Println("Former dependencies become direct installs in the rebuilt env")
+			snapshot, err := envdiff.FromEnv(ctx, name)
+			if err != nil {
+				return err
+			}
+			for pkg := range snapshot.Packages {
+				packages = append(packages, pkg)
+			}
+			sort.Strings(packages)
+		}
+
+		fmt.Printf("Installing Python %s...\n", version)
+		if err := uv.InstallPython(ctx, version); err != nil {
+			return fmt.Errorf("failed to install Python: %w", err)
+		}
+
+		fmt.Printf("Building %s on Python %s...\n", name, version)
+		staging, err := env.Stage(ctx, name, version)
+		if err != nil {
+			return err
+		}
+
+		if err := reinstall(ctx, uv.VenvPython(staging), packages, meta.Requirements); err != nil {
+			os.RemoveAll(staging)
+			return err
+		}
+
+		if err := env.Swap(name, staging); err != nil {
+			return err
+		}
+
+		meta.Python = version
+		if actual, err := env.PythonVersion(name); err == nil && actual != "" {
+			meta.Python = actual
+		}
+		if err := env.SaveMeta(name, meta); err != nil {
+			return err
+		}
+
+		fmt.Printf("Environment %s now uses Python %s\n", name, version)
+		return nil
+	},
+}
+
+// reinstall installs packages and requirements files into a staged env. If
+// the combined install fails, each entry is retried alone to report which
+// ones have no release compatible with the new interpreter.
+func reinstall(ctx context.Context, python string, packages []string, requirements []string) error {
+	args := []string{"pip", "install"}
+	for _, pkg := range packages {
+		args = append(args, env.PackageArgs(pkg)...)
+	}
+	for _, file := range requirements {
+		args = append(args, "-r", file)
+	}
+	if len(args) == 2 {
+		return nil
+	}
+
+	err := uv.RunUvWithPython(ctx, python, args...)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return err
+	}
+
+	var failed []string
+	for _, pkg := range packages {
+		if err := uv.RunUvWithPython(ctx, python, append([]string{"pip", "install"}, env.PackageArgs(pkg)...)...); err != nil {
+			failed = append(failed, pkg)
+		}
+	}
+	for _, file := range requirements {
+		if err := uv.RunUvWithPython(ctx, python, "pip", "install", "-r", file); err != nil {
+			failed = append(failed, "-r "+file)
+		}
+	}
+
+	if len(failed) == 0 {
+		return fmt.Errorf("failed to reinstall packages; the original environment is unchanged")
+	}
+	return fmt.Errorf("no compatible release for: %s; the original environment is unchanged", strings.Join(failed, ", "))
+}
@@ -7,3 +7,5 @@ toolchain go1.22.0
 require github.com/urfave/cli/v3 v3.6.2
 
 require github.com/BurntSushi/toml v1.6.0
+
+require golang.org/x/sys v0.30.0
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+
+	"github.com/BurntSushi/toml"
 )
 
 var HomeDir = filepath.Join(os.Getenv("HOME"), ".uda")
@@ -11,7 +13,7 @@ func Init() error {
 	dirs := []string{
 		HomeDir,
 		filepath.Join(HomeDir, "envs"),
-		filepath.Join(HomeDir, "cache"),
+		CachePath(),
 	}
 
 	for _, dir := range dirs {
@@ -26,6 +28,10 @@ func UvPath() string {
 	return filepath.Join(HomeDir, "uv")
 }
 
+func CachePath() string {
+	return filepath.Join(HomeDir, "cache")
+}
+
 func EnvsPath() string {
 	return filepath.Join(HomeDir, "envs")
 }
@@ -34,6 +40,11 @@ func EnvPath(name string) string {
 	return filepath.Join(EnvsPath(), name)
 }
 
+// EnvMetaPath returns the path of the uda metadata file inside an environment
+func EnvMetaPath(name string) string {
+	return filepath.Join(EnvPath(name), "uda.toml")
+}
+
 func ConfigPath() string {
 	return filepath.Join(HomeDir, "config.toml")
 }
@@ -45,7 +56,60 @@ type MirrorConfig struct {
 	Priority int    `toml:"priority"`
 }
 
+// Template represents a named set of defaults for `create --template`
+type Template struct {
+	Python   string            `toml:"python,omitempty"`
+	Packages []string          `toml:"packages,omitempty"`
+	Env      map[string]string `toml:"env,omitempty"`
+}
+
 // Config represents the application configuration
 type Config struct {
-	Mirror *MirrorConfig `toml:"mirror"`
+	Mirror                *MirrorConfig        `toml:"mirror"`
+	DefaultPython         string               `toml:"default_python,omitempty"`
+	CreateDefaultPackages []string             `toml:"create_default_packages,omitempty"`
+	Templates             map[string]*Template `toml:"templates,omitempty"`
+	// AutoActivate makes the init script activate DefaultEnv at shell startup
+	AutoActivate bool   `toml:"auto_activate,omitempty"`
+	DefaultEnv   string `toml:"default_env,omitempty"`
+	// EnvPrompt formats the prompt prefix, e.g. "({name}|py{python_version}) "
+	EnvPrompt string `toml:"env_prompt,omitempty"`
+	// ChangePS1 set to false leaves the shell prompt untouched; the prefix
+	// is still exported as UDA_PROMPT_MODIFIER
+	ChangePS1 *bool `toml:"changeps1,omitempty"`
+	// PipShim makes the init script route pip and pip3 to `uda pip` while
+	// an env is active
+	PipShim bool `toml:"pip_shim,omitempty"`
+}
+
+// Load loads configuration from file
+func Load() (*Config, error) {
+	var cfg Config
+
+	_, err := toml.DecodeFile(ConfigPath(), &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// LoadOrDefault loads configuration, returning an empty one when the file is missing
+func LoadOrDefault() (*Config, error) {
+	cfg, err := Load()
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	return cfg, err
+}
+
+// Save writes configuration to file
+func Save(cfg *Config) error {
+	file, err := os.Create(ConfigPath())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return toml.NewEncoder(file).Encode(cfg)
 }
@@ -0,0 +1,135 @@
+// Package dotenv reads .env files
+package dotenv
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Parse reads KEY=VALUE assignments in dotenv syntax. Blank lines and lines
+// starting with # are skipped and an "export " prefix is allowed. Single
+// quoted values are literal; double quoted values may span lines and
+// understand \n, \t, \", \\ and \$. Unquoted values end at " #" and are
+// trimmed. Values are never expanded.
+func Parse(data string) (map[string]string, error) {
+	vars := make(map[string]string)
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	line := 0
+
+	for len(data) > 0 {
+		line++
+		var current string
+		current, data, _ = strings.Cut(data, "\n")
+		current = strings.TrimSpace(current)
+		if current == "" || strings.HasPrefix(current, "#") {
+			continue
+		}
+
+		current = strings.TrimPrefix(current, "export ")
+		key, value, ok := strings.Cut(current, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !validKey(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", line)
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single quote", line)
+			}
+			if !onlyComment(value[end+2:]) {
+				return nil, fmt.Errorf("line %d: unexpected text after closing quote", line)
+			}
+			value = value[1 : end+1]
+		case strings.HasPrefix(value, `"`):
+			// The value may continue on the following lines
+			start := line
+			rest := value[1:] + "\n" + data
+			parsed, n, err := unquote(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", start, err)
+			}
+			consumed := rest[:n]
+			line += strings.Count(consumed, "\n")
+			tail, remaining, _ := strings.Cut(rest[n:], "\n")
+			if !onlyComment(tail) {
+				return nil, fmt.Errorf("line %d: unexpected text after closing quote", line)
+			}
+			data = remaining
+			value = parsed
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = value[:i]
+			}
+			value = strings.TrimSpace(value)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// ParseFile reads a dotenv file
+func ParseFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// unquote decodes s up to its closing double quote and returns the value
+// and the number of bytes consumed, including the quote
+func unquote(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 == len(s) {
+				break
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '"', '\\', '$':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated double quote")
+}
+
+func onlyComment(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || strings.HasPrefix(s, "#")
+}
+
+func validKey(key string) bool {
+	if key == "" || key[0] >= '0' && key[0] <= '9' {
+		return false
+	}
+	for _, c := range key {
+		if !(c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
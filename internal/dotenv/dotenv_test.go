@@ -0,0 +1,51 @@
+package dotenv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	vars, err := Parse(`# service config
+export DATABASE_URL=postgres://db/app
+PORT = 8080   # inline comment
+EMPTY=
+HASH=a#b
+SINGLE='literal $HOME \n'
+DOUBLE="tab\there \"quoted\""
+MULTI="first
+second"
+AFTER=yes
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"DATABASE_URL": "postgres://db/app",
+		"PORT":         "8080",
+		"EMPTY":        "",
+		"HASH":         "a#b",
+		"SINGLE":       `literal $HOME \n`,
+		"DOUBLE":       "tab\there \"quoted\"",
+		"MULTI":        "first\nsecond",
+		"AFTER":        "yes",
+	}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Fatalf("unexpected vars: %#v", vars)
+	}
+}
+
+func TestParseReportsLine(t *testing.T) {
+	cases := map[string]string{
+		"A=1\nnot an assignment\n": "line 2: expected KEY=VALUE",
+		"A=1\nB=\"open\n\n":        "line 2: unterminated double quote",
+		"A='x' y\n":                "line 1: unexpected text after closing quote",
+		"1A=x\n":                   "line 1: expected KEY=VALUE",
+		"M=\"a\nb\"\nbad\n":        "line 3: expected KEY=VALUE",
+	}
+	for input, expected := range cases {
+		if _, err := Parse(input); err == nil || err.Error() != expected {
+			t.Fatalf("Parse(%q): expected %q, got %v", input, expected, err)
+		}
+	}
+}
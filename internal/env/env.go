@@ -1,9 +1,13 @@
 package env
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/uda/uda/internal/config"
 	"github.com/uda/uda/internal/uv"
@@ -29,8 +33,18 @@ func Exists(name string) bool {
 	return err == nil
 }
 
-func Create(name string, pythonVersion string) error {
-	envPath := config.EnvPath(name)
+func Create(ctx context.Context, name string, pythonVersion string) error {
+	if err := createVenv(ctx, config.EnvPath(name), pythonVersion, os.Stdin, os.Stdout, os.Stderr); err != nil {
+		return err
+	}
+
+	fmt.Printf("Environment %s created successfully!\n", name)
+	return nil
+}
+
+// createVenv runs uv venv. With a nil stdin uv runs in the background, so
+// builds running in parallel never compete for the terminal.
+func createVenv(ctx context.Context, envPath string, pythonVersion string, stdin io.Reader, stdout, stderr io.Writer, extraArgs ...string) error {
 	if err := os.MkdirAll(envPath, 0755); err != nil {
 		return fmt.Errorf("failed to create env directory: %w", err)
 	}
@@ -46,17 +60,21 @@ func Create(name string, pythonVersion string) error {
 	if pythonVersion != "" {
 		args = append(args, "--python", pythonVersion)
 	}
+	args = append(args, extraArgs...)
 
-	cmd := exec.Command(uvPath, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+	var cmd *exec.Cmd
+	if stdin != nil {
+		cmd = uv.Command(ctx, uvPath, args...)
+		cmd.Stdin = stdin
+	} else {
+		cmd = uv.BackgroundCommand(ctx, uvPath, args...)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to create venv: %w", err)
 	}
-
-	fmt.Printf("Environment %s created successfully!\n", name)
 	return nil
 }
 
@@ -64,3 +82,63 @@ func Remove(name string) error {
 	envPath := config.EnvPath(name)
 	return os.RemoveAll(envPath)
 }
+
+// StagingPath returns where a replacement for an environment is built
+func StagingPath(name string) string {
+	return filepath.Join(config.CachePath(), "staging", name)
+}
+
+// Stage creates a relocatable venv for name in its staging directory. The
+// venv is relocatable so it still works after Swap moves it into place.
+func Stage(ctx context.Context, name string, pythonVersion string) (string, error) {
+	staging := StagingPath(name)
+	if err := os.RemoveAll(staging); err != nil {
+		return "", err
+	}
+
+	if err := createVenv(ctx, staging, pythonVersion, os.Stdin, os.Stdout, os.Stderr, "--relocatable"); err != nil {
+		os.RemoveAll(staging)
+		return "", err
+	}
+	return staging, nil
+}
+
+// errExchangeUnsupported means the platform or filesystem cannot exchange
+// two directories atomically
+var errExchangeUnsupported = errors.New("atomic exchange is not supported")
+
+// Swap atomically replaces an environment with a staged one, keeping its
+// activate and deactivate hooks. The two directories are exchanged in one
+// step, so the env path always holds a complete env, and the old env is
+// removed afterwards. Where that is not supported, the env is moved aside
+// and restored if the staged env cannot be moved into place.
+func Swap(name string, staging string) error {
+	envPath := config.EnvPath(name)
+
+	if err := copyHooks(envPath, staging); err != nil {
+		return fmt.Errorf("failed to carry over hooks of %s: %w", name, err)
+	}
+
+	err := exchange(staging, envPath)
+	if err == nil {
+		return os.RemoveAll(staging)
+	}
+	if !errors.Is(err, errExchangeUnsupported) {
+		return fmt.Errorf("failed to swap in new env: %w", err)
+	}
+
+	backup := staging + ".old"
+	if err := os.RemoveAll(backup); err != nil {
+		return err
+	}
+	if err := os.Rename(envPath, backup); err != nil {
+		return fmt.Errorf("failed to move %s aside: %w", name, err)
+	}
+	if err := os.Rename(staging, envPath); err != nil {
+		if restoreErr := os.Rename(backup, envPath); restoreErr != nil {
+			return fmt.Errorf("failed to swap in new env (%v) and to restore the old one: %w", err, restoreErr)
+		}
+		return fmt.Errorf("failed to swap in new env: %w", err)
+	}
+	return os.RemoveAll(backup)
+}
@@ -0,0 +1,48 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/uda/uda/internal/config"
+)
+
+func TestSwapReplacesEnvAndKeepsHooks(t *testing.T) {
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+
+	envPath := config.EnvPath("proj")
+	staging := StagingPath("proj")
+	for path, marker := range map[string]string{envPath: "old", staging: "new"} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatalf("prepare %s: %v", path, err)
+		}
+		if err := os.WriteFile(filepath.Join(path, "marker"), []byte(marker), 0644); err != nil {
+			t.Fatalf("write marker: %v", err)
+		}
+	}
+	hookDir := filepath.Join(envPath, ActivateHooksDir)
+	if err := os.MkdirAll(hookDir, 0755); err != nil {
+		t.Fatalf("prepare hook dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(hookDir, "10-a.sh"), []byte("true\n"), 0644); err != nil {
+		t.Fatalf("write hook: %v", err)
+	}
+
+	if err := Swap("proj", staging); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(envPath, "marker")); err != nil || string(data) != "new" {
+		t.Fatalf("expected the staged env in place, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(envPath, ActivateHooksDir, "10-a.sh")); err != nil {
+		t.Fatalf("expected hooks to be carried over: %v", err)
+	}
+	for _, leftover := range []string{staging, staging + ".old"} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, got %v", leftover, err)
+		}
+	}
+}
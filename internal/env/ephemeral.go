@@ -0,0 +1,183 @@
+package env
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uda/uda/internal/config"
+	"github.com/uda/uda/internal/uv"
+)
+
+// EphemeralPath returns the directory of the cached ephemeral env with key
+func EphemeralPath(key string) string {
+	return filepath.Join(ephemeralDir(), key)
+}
+
+func ephemeralDir() string {
+	return filepath.Join(config.CachePath(), "envs")
+}
+
+// EphemeralKey identifies an ephemeral env by its Python request and
+// package specs. The order of the specs does not matter.
+func EphemeralKey(python string, specs []string) string {
+	sorted := append([]string(nil), specs...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "python=%s\n", python)
+	for _, spec := range sorted {
+		fmt.Fprintf(h, "spec=%s\n", spec)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Ephemeral returns the path of a cached env with python and specs
+// installed, building it on first use, and whether it was built now. The
+// env is built next to its final place and renamed into it, so a half-built
+// env is never reused and concurrent builds of the same key are safe. uv
+// does not read the terminal, and its output goes to stdout and stderr.
+func Ephemeral(ctx context.Context, python string, specs []string, stdout, stderr io.Writer) (string, bool, error) {
+	key := EphemeralKey(python, specs)
+	path := EphemeralPath(key)
+	if _, err := os.Stat(filepath.Join(path, "uda.toml")); err == nil {
+		touchEphemeral(path)
+		return path, false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", false, err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(path), key+".tmp-")
+	if err != nil {
+		return "", false, err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := createVenv(ctx, tmp, python, nil, stdout, stderr, "--relocatable"); err != nil {
+		return "", false, err
+	}
+
+	if len(specs) > 0 {
+		if err := InstallInto(ctx, tmp, specs, stdout, stderr); err != nil {
+			return "", false, err
+		}
+	}
+
+	meta := &Meta{Python: python, Packages: specs}
+	if version, err := PythonVersionAt(tmp); err == nil && version != "" {
+		meta.Python = version
+	}
+	if err := saveMetaFile(filepath.Join(tmp, "uda.toml"), meta); err != nil {
+		return "", false, err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		// Another process finished the same env first
+		if _, statErr := os.Stat(filepath.Join(path, "uda.toml")); statErr == nil {
+			return path, true, nil
+		}
+		return "", false, fmt.Errorf("failed to cache env: %w", err)
+	}
+	return path, true, nil
+}
+
+// InstallInto runs uv pip install with args against the venv at envPath
+func InstallInto(ctx context.Context, envPath string, args []string, stdout, stderr io.Writer) error {
+	uvPath, err := uv.FindUv()
+	if err != nil {
+		return err
+	}
+
+	install := uv.BackgroundCommand(ctx, uvPath, append([]string{"pip", "install", "--python", uv.VenvPython(envPath)}, args...)...)
+	install.Stdout = stdout
+	install.Stderr = stderr
+	if err := install.Run(); err != nil {
+		return fmt.Errorf("failed to install %s: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// touchEphemeral marks an ephemeral env as used now, which is what cache
+// pruning goes by
+func touchEphemeral(path string) {
+	now := time.Now()
+	os.Chtimes(filepath.Join(path, "uda.toml"), now, now)
+}
+
+// CachedEnv is an ephemeral env in the cache
+type CachedEnv struct {
+	Key      string
+	Path     string
+	Meta     *Meta
+	LastUsed time.Time
+}
+
+// ListEphemeral returns the ephemeral envs in the cache
+func ListEphemeral() ([]CachedEnv, error) {
+	dir := ephemeralDir()
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var envs []CachedEnv
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.Contains(entry.Name(), ".tmp-") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		metaPath := filepath.Join(path, "uda.toml")
+		info, err := os.Stat(metaPath)
+		if err != nil {
+			continue
+		}
+		meta, err := loadMetaFile(metaPath)
+		if err != nil {
+			meta = &Meta{}
+		}
+		envs = append(envs, CachedEnv{Key: entry.Name(), Path: path, Meta: meta, LastUsed: info.ModTime()})
+	}
+	return envs, nil
+}
+
+// staleBuildAge is when a leftover build directory is considered abandoned
+const staleBuildAge = time.Hour
+
+// PruneEphemeral removes ephemeral envs not used for olderThan, and build
+// directories left behind by interrupted builds, and returns the removed envs
+func PruneEphemeral(olderThan time.Duration) ([]CachedEnv, error) {
+	envs, err := ListEphemeral()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []CachedEnv
+	for _, e := range envs {
+		if time.Since(e.LastUsed) < olderThan {
+			continue
+		}
+		if err := os.RemoveAll(e.Path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", e.Path, err)
+		}
+		removed = append(removed, e)
+	}
+
+	builds, _ := filepath.Glob(filepath.Join(ephemeralDir(), "*.tmp-*"))
+	for _, build := range builds {
+		if info, err := os.Stat(build); err == nil && time.Since(info.ModTime()) > staleBuildAge {
+			os.RemoveAll(build)
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,74 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/uda/uda/internal/uv"
+)
+
+// Hook directories inside an environment, sourced on activate and deactivate
+const (
+	ActivateHooksDir   = "activate.d"
+	DeactivateHooksDir = "deactivate.d"
+)
+
+// Hooks returns the scripts with the given extension (".sh", ".fish") in a
+// hook directory of the env at envPath, sorted so they run in a stable order.
+// An empty envPath has no hooks, rather than resolving dir against the
+// current directory.
+func Hooks(envPath string, dir string, ext string) []string {
+	if envPath == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(filepath.Join(envPath, dir))
+	if err != nil {
+		return nil
+	}
+
+	var hooks []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ext) {
+			hooks = append(hooks, filepath.Join(envPath, dir, entry.Name()))
+		}
+	}
+	sort.Strings(hooks)
+	return hooks
+}
+
+// copyHooks copies the hook directories of one env directory into another
+func copyHooks(from string, to string) error {
+	for _, dir := range []string{ActivateHooksDir, DeactivateHooksDir} {
+		src := filepath.Join(from, dir)
+		entries, err := os.ReadDir(src)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		dst := filepath.Join(to, dir)
+		if err := os.MkdirAll(dst, 0755); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if err := uv.CopyFile(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+			if err := os.Chmod(filepath.Join(dst, entry.Name()), info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/uda/uda/internal/config"
+	"github.com/uda/uda/internal/project"
+)
+
+// BindFile is the file written into a project directory to bind it to an env
+const BindFile = ".uda-env"
+
+// Meta represents the uda metadata stored inside an environment
+type Meta struct {
+	Python       string            `toml:"python,omitempty"`
+	Project      string            `toml:"project,omitempty"`
+	Template     string            `toml:"template,omitempty"`
+	Packages     []string          `toml:"packages,omitempty"`
+	Requirements []string          `toml:"requirements,omitempty"`
+	Vars         map[string]string `toml:"vars,omitempty"`
+}
+
+// LoadMeta reads the metadata of an environment; a missing file yields empty metadata
+func LoadMeta(name string) (*Meta, error) {
+	meta, err := loadMetaFile(config.EnvMetaPath(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata of %s: %w", name, err)
+	}
+	return meta, nil
+}
+
+func loadMetaFile(path string) (*Meta, error) {
+	var meta Meta
+	_, err := toml.DecodeFile(path, &meta)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// SaveMeta writes the metadata of an environment
+func SaveMeta(name string, meta *Meta) error {
+	return saveMetaFile(config.EnvMetaPath(name), meta)
+}
+
+func saveMetaFile(path string, meta *Meta) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return toml.NewEncoder(file).Encode(meta)
+}
+
+// AddPackages records requested package specs, replacing earlier specs of the same package
+func (m *Meta) AddPackages(specs ...string) {
+	for _, spec := range specs {
+		name := RequirementName(spec)
+		replaced := false
+		for i, existing := range m.Packages {
+			if RequirementName(existing) == name {
+				m.Packages[i] = spec
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			m.Packages = append(m.Packages, spec)
+		}
+	}
+}
+
+// RemovePackages forgets the recorded specs of the named packages
+func (m *Meta) RemovePackages(names ...string) {
+	remove := make(map[string]bool)
+	for _, name := range names {
+		remove[RequirementName(name)] = true
+	}
+
+	kept := m.Packages[:0]
+	for _, spec := range m.Packages {
+		if !remove[RequirementName(spec)] {
+			kept = append(kept, spec)
+		}
+	}
+	m.Packages = kept
+}
+
+// RemoveRequirements forgets recorded requirements files
+func (m *Meta) RemoveRequirements(files ...string) {
+	remove := make(map[string]bool)
+	for _, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			file = abs
+		}
+		remove[file] = true
+	}
+
+	kept := m.Requirements[:0]
+	for _, file := range m.Requirements {
+		if !remove[file] {
+			kept = append(kept, file)
+		}
+	}
+	m.Requirements = kept
+}
+
+// AddRequirements records requirements files, skipping ones already recorded
+func (m *Meta) AddRequirements(files ...string) {
+	for _, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			file = abs
+		}
+		found := false
+		for _, existing := range m.Requirements {
+			if existing == file {
+				found = true
+				break
+			}
+		}
+		if !found {
+			m.Requirements = append(m.Requirements, file)
+		}
+	}
+}
+
+var nameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// NormalizeName normalizes a package name as described in PEP 503
+func NormalizeName(name string) string {
+	return strings.ToLower(nameSeparators.ReplaceAllString(strings.TrimSpace(name), "-"))
+}
+
+// EditablePrefix starts a recorded editable install, e.g. "-e /src/proj"
+const EditablePrefix = "-e "
+
+// RequirementName returns the normalized package name of a requirement spec
+// or a recorded editable install
+func RequirementName(spec string) string {
+	spec = strings.TrimSpace(spec)
+	if target, ok := strings.CutPrefix(spec, EditablePrefix); ok {
+		return editableName(strings.TrimSpace(target))
+	}
+	// Local projects and files are recorded by absolute path
+	if path, _, _ := strings.Cut(spec, "["); filepath.IsAbs(path) {
+		if name := LocalPackageName(path); name != "" {
+			return name
+		}
+	}
+	if i := strings.IndexAny(spec, "<>=!~;[@( "); i >= 0 {
+		spec = spec[:i]
+	}
+	return NormalizeName(spec)
+}
+
+// editableName names an editable target: the #egg= fragment of a VCS URL,
+// or the project name of a local directory, falling back to its base name
+func editableName(target string) string {
+	if _, egg, ok := strings.Cut(target, "#egg="); ok {
+		name, _, _ := strings.Cut(egg, "&")
+		return NormalizeName(name)
+	}
+	path, _, _ := strings.Cut(target, "[")
+	if name := LocalPackageName(path); name != "" {
+		return name
+	}
+	return NormalizeName(strings.TrimSuffix(filepath.Base(path), ".git"))
+}
+
+// LocalPackageName returns the distribution name of a project directory or
+// a wheel or sdist file, or "" for other paths
+func LocalPackageName(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	if info.IsDir() {
+		// Projects without a [project] name are named after their directory
+		name := filepath.Base(path)
+		if p, err := project.Load(path, nil); err == nil && p.Name != "" {
+			name = p.Name
+		}
+		return NormalizeName(name)
+	}
+
+	base := filepath.Base(path)
+	if stem, ok := strings.CutSuffix(base, ".whl"); ok {
+		name, _, _ := strings.Cut(stem, "-")
+		return NormalizeName(name)
+	}
+	for _, ext := range []string{".tar.gz", ".zip"} {
+		if stem, ok := strings.CutSuffix(base, ext); ok {
+			if i := strings.LastIndex(stem, "-"); i > 0 {
+				return NormalizeName(stem[:i])
+			}
+		}
+	}
+	return ""
+}
+
+// PackageArgs returns the pip install arguments of a recorded package spec
+func PackageArgs(spec string) []string {
+	if target, ok := strings.CutPrefix(spec, EditablePrefix); ok {
+		return []string{"-e", strings.TrimSpace(target)}
+	}
+	return []string{spec}
+}
+
+// Bind records dir as the project of an environment and marks dir with BindFile
+func Bind(name string, dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, BindFile), []byte(name+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to bind %s: %w", dir, err)
+	}
+
+	meta, err := LoadMeta(name)
+	if err != nil {
+		return err
+	}
+	meta.Project = dir
+	return SaveMeta(name, meta)
+}
+
+// Bound returns the environment bound to dir or one of its parents, or "" if none
+func Bound(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, BindFile))
+		if err == nil {
+			return strings.TrimSpace(string(data))
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+var varName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateVarName rejects names that are not shell identifiers or that uda manages itself
+func ValidateVarName(name string) error {
+	if !varName.MatchString(name) {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+	if name == "PATH" || name == "VIRTUAL_ENV" || strings.HasPrefix(name, "_UDA_") {
+		return fmt.Errorf("variable %s is managed by uda and cannot be set per env", name)
+	}
+	return nil
+}
+
+// SortedVars returns the variable names of the metadata in a stable order
+func (m *Meta) SortedVars() []string {
+	names := make([]string, 0, len(m.Vars))
+	for name := range m.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,86 @@
+package env
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/uda/uda/internal/config"
+)
+
+// PyvenvCfg reads the key = value pairs of an environment's pyvenv.cfg
+func PyvenvCfg(name string) (map[string]string, error) {
+	return pyvenvCfgAt(config.EnvPath(name))
+}
+
+func pyvenvCfgAt(envPath string) (map[string]string, error) {
+	file, err := os.Open(filepath.Join(envPath, "pyvenv.cfg"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cfg := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		cfg[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return cfg, scanner.Err()
+}
+
+// PythonVersion returns the interpreter version recorded in pyvenv.cfg
+func PythonVersion(name string) (string, error) {
+	return PythonVersionAt(config.EnvPath(name))
+}
+
+// PythonVersionAt is PythonVersion for a venv directory
+func PythonVersionAt(envPath string) (string, error) {
+	cfg, err := pyvenvCfgAt(envPath)
+	if err != nil {
+		return "", err
+	}
+
+	// uv writes version_info, the stdlib venv module writes version
+	if v := cfg["version_info"]; v != "" {
+		return v, nil
+	}
+	return cfg["version"], nil
+}
+
+// UsersOf returns the environments whose base interpreter is the given python
+// executable. A non-empty version also has to match, since several versions
+// may share a bin directory such as /usr/bin.
+func UsersOf(python string, version string) ([]string, error) {
+	envs, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := resolveDir(filepath.Dir(python))
+	var users []string
+	for _, name := range envs {
+		cfg, err := PyvenvCfg(name)
+		if err != nil {
+			continue
+		}
+		if version != "" && cfg["version_info"] != "" && cfg["version_info"] != version {
+			continue
+		}
+		if home := cfg["home"]; home != "" && resolveDir(home) == dir {
+			users = append(users, name)
+		}
+	}
+	return users, nil
+}
+
+func resolveDir(dir string) string {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		return resolved
+	}
+	return filepath.Clean(dir)
+}
@@ -0,0 +1,161 @@
+package env
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/uda/uda/internal/config"
+	"github.com/uda/uda/internal/uv"
+)
+
+// BinDir returns the directory of an environment's executables
+func BinDir(name string) string {
+	return venvBinDir(config.EnvPath(name))
+}
+
+func venvBinDir(envPath string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(envPath, "Scripts")
+	}
+	return filepath.Join(envPath, "bin")
+}
+
+// Environ returns base as it would look after activating an environment.
+// Later steps win: the env's variables are applied to base, then overrides,
+// and finally the bin dir of a previously active env is dropped from PATH,
+// the env's bin dir is put in front, VIRTUAL_ENV is set and PYTHONHOME is
+// removed.
+func Environ(name string, base []string, overrides map[string]string) ([]string, error) {
+	meta, err := LoadMeta(name)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+	for key, value := range meta.Vars {
+		vars[key] = value
+	}
+	for key, value := range overrides {
+		vars[key] = value
+	}
+	return EnvironAt(config.EnvPath(name), base, vars), nil
+}
+
+// EnvironAt is Environ for a venv outside the envs directory, such as a
+// cached ephemeral env, with vars in place of the env's variables
+func EnvironAt(envPath string, base []string, vars map[string]string) []string {
+	merged := make(map[string]string)
+	for _, kv := range base {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			merged[key] = value
+		}
+	}
+	for key, value := range vars {
+		merged[key] = value
+	}
+
+	binDir := venvBinDir(envPath)
+	var path []string
+	for _, entry := range filepath.SplitList(merged["PATH"]) {
+		if old := merged["VIRTUAL_ENV"]; old != "" && entry == filepath.Join(old, filepath.Base(binDir)) {
+			continue
+		}
+		path = append(path, entry)
+	}
+	merged["PATH"] = strings.Join(append([]string{binDir}, path...), string(os.PathListSeparator))
+	merged["VIRTUAL_ENV"] = envPath
+	delete(merged, "PYTHONHOME")
+
+	environ := make([]string, 0, len(merged))
+	for key, value := range merged {
+		environ = append(environ, key+"="+value)
+	}
+	sort.Strings(environ)
+	return environ
+}
+
+// minimalVars are kept from the process environment by MinimalEnviron
+var minimalVars = []string{"HOME", "USER", "LOGNAME", "SHELL", "TERM", "LANG", "LC_ALL", "TZ", "TMPDIR",
+	"SYSTEMROOT", "COMSPEC", "PATHEXT", "TEMP", "TMP", "USERPROFILE"}
+
+// MinimalEnviron returns the base for a command that should not inherit the
+// caller's environment: the user, locale and temp dir variables and a
+// default system PATH
+func MinimalEnviron() []string {
+	var environ []string
+	for _, key := range minimalVars {
+		if value, ok := os.LookupEnv(key); ok {
+			environ = append(environ, key+"="+value)
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return append(environ, "PATH="+os.Getenv("PATH"))
+	}
+	return append(environ, "PATH=/usr/local/bin:/usr/bin:/bin")
+}
+
+// Command prepares a command to run inside an environment with environ,
+// usually from Environ. A bare command name is looked up on its PATH, so the
+// env's own scripts win over system ones.
+func Command(environ []string, command string, args ...string) (*exec.Cmd, error) {
+	path, err := resolveCommand(command, environ)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Args[0] = command
+	cmd.Env = environ
+	return cmd, nil
+}
+
+// CommandContext is Command for a non-interactive child bound to ctx. It
+// runs in its own process group, which is stopped when ctx is cancelled.
+func CommandContext(ctx context.Context, environ []string, command string, args ...string) (*exec.Cmd, error) {
+	path, err := resolveCommand(command, environ)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := uv.BackgroundCommand(ctx, path, args...)
+	cmd.Args[0] = command
+	cmd.Env = environ
+	return cmd, nil
+}
+
+// resolveCommand looks a bare command name up on the PATH of environ
+func resolveCommand(command string, environ []string) (string, error) {
+	if strings.ContainsRune(command, filepath.Separator) || strings.ContainsRune(command, '/') {
+		return command, nil
+	}
+	return lookPath(command, environ)
+}
+
+// lookPath finds an executable on the PATH of environ
+func lookPath(command string, environ []string) (string, error) {
+	for _, kv := range environ {
+		if value, ok := strings.CutPrefix(kv, "PATH="); ok {
+			for _, dir := range filepath.SplitList(value) {
+				if dir == "" {
+					dir = "."
+				}
+				candidate := filepath.Join(dir, command)
+				if runtime.GOOS == "windows" {
+					if found, err := exec.LookPath(candidate); err == nil {
+						return found, nil
+					}
+					continue
+				}
+				if info, err := os.Stat(candidate); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+					return candidate, nil
+				}
+			}
+		}
+	}
+	return "", &exec.Error{Name: command, Err: exec.ErrNotFound}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package env
+
+// exchange atomically swaps the directories at a and b
+func exchange(a, b string) error {
+	return errExchangeUnsupported
+}
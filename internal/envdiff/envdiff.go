@@ -0,0 +1,245 @@
+package envdiff
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/uda/uda/internal/env"
+	"github.com/uda/uda/internal/pyver"
+	"github.com/uda/uda/internal/uv"
+)
+
+// Snapshot is the Python version and installed packages of one side of a diff
+type Snapshot struct {
+	Name     string
+	Python   string
+	Packages map[string]string
+}
+
+// Change kinds
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change describes one package that differs between two snapshots
+type Change struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// Result is the difference between two snapshots
+type Result struct {
+	A        string   `json:"a"`
+	B        string   `json:"b"`
+	PythonA  string   `json:"python_a,omitempty"`
+	PythonB  string   `json:"python_b,omitempty"`
+	Packages []Change `json:"packages"`
+}
+
+// Load builds a snapshot from an environment name or a spec/lock file path
+func Load(ctx context.Context, target string) (*Snapshot, error) {
+	if env.Exists(target) {
+		return FromEnv(ctx, target)
+	}
+	if _, err := os.Stat(target); err == nil {
+		return FromFile(target)
+	}
+	return nil, fmt.Errorf("%s is neither an environment nor a file", target)
+}
+
+// FromEnv snapshots an environment with uv pip freeze
+func FromEnv(ctx context.Context, name string) (*Snapshot, error) {
+	python, err := env.PythonVersion(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Python version of %s: %w", name, err)
+	}
+
+	out, err := uv.OutputUvWithPython(ctx, uv.GetPythonPath(name), "pip", "freeze")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list packages of %s: %w", name, err)
+	}
+
+	return &Snapshot{Name: name, Python: python, Packages: ParseRequirements(out)}, nil
+}
+
+// FromFile snapshots a uv.lock, pylock.toml or requirements-style file
+func FromFile(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{Name: path}
+	base := filepath.Base(path)
+	if base == "uv.lock" || strings.HasPrefix(base, "pylock.") && strings.HasSuffix(base, ".toml") {
+		var lock struct {
+			RequiresPython string `toml:"requires-python"`
+			Package        []struct {
+				Name    string `toml:"name"`
+				Version string `toml:"version"`
+			} `toml:"package"`
+			Packages []struct {
+				Name    string `toml:"name"`
+				Version string `toml:"version"`
+			} `toml:"packages"`
+		}
+		if _, err := toml.Decode(string(data), &lock); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		s.Python = lock.RequiresPython
+		s.Packages = make(map[string]string)
+		for _, p := range append(lock.Package, lock.Packages...) {
+			s.Packages[env.NormalizeName(p.Name)] = p.Version
+		}
+		return s, nil
+	}
+
+	s.Packages = ParseRequirements(data)
+	return s, nil
+}
+
+// ParseRequirements reads pip freeze / requirements.txt lines into name -> version.
+// Unpinned requirements map to their specifier, or "" when there is none.
+func ParseRequirements(data []byte) map[string]string {
+	packages := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+
+		if name, url, ok := strings.Cut(line, " @ "); ok {
+			packages[env.NormalizeName(name)] = strings.TrimSpace(url)
+			continue
+		}
+		if name, version, ok := strings.Cut(line, "=="); ok {
+			packages[env.NormalizeName(stripExtras(name))] = strings.TrimSpace(version)
+			continue
+		}
+
+		i := strings.IndexAny(line, "<>=!~ ")
+		if i < 0 {
+			packages[env.NormalizeName(stripExtras(line))] = ""
+			continue
+		}
+		packages[env.NormalizeName(stripExtras(line[:i]))] = strings.TrimSpace(line[i:])
+	}
+	return packages
+}
+
+func stripExtras(name string) string {
+	if i := strings.Index(name, "["); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// Compare returns the changes needed to go from a to b
+func Compare(a, b *Snapshot) *Result {
+	r := &Result{A: a.Name, B: b.Name, PythonA: a.Python, PythonB: b.Python, Packages: []Change{}}
+
+	for name, oldVersion := range a.Packages {
+		newVersion, ok := b.Packages[name]
+		switch {
+		case !ok:
+			r.Packages = append(r.Packages, Change{Name: name, Kind: Removed, Old: oldVersion})
+		case oldVersion != newVersion:
+			r.Packages = append(r.Packages, Change{Name: name, Kind: Changed, Old: oldVersion, New: newVersion})
+		}
+	}
+	for name, newVersion := range b.Packages {
+		if _, ok := a.Packages[name]; !ok {
+			r.Packages = append(r.Packages, Change{Name: name, Kind: Added, New: newVersion})
+		}
+	}
+
+	sort.Slice(r.Packages, func(i, j int) bool {
+		return r.Packages[i].Name < r.Packages[j].Name
+	})
+	return r
+}
+
+// Empty reports whether the two snapshots are identical
+func (r *Result) Empty() bool {
+	return !r.pythonChanged() && len(r.Packages) == 0
+}
+
+// pythonChanged reports whether the Python sides disagree. A side without a
+// Python, such as a requirements file, matches anything, and a range such as
+// uv.lock's requires-python matches the versions it contains.
+func (r *Result) pythonChanged() bool {
+	a, b := r.PythonA, r.PythonB
+	if a == "" || b == "" || a == b {
+		return false
+	}
+	if pyver.IsSpecifier(a) == pyver.IsSpecifier(b) {
+		return true
+	}
+	if pyver.IsSpecifier(a) {
+		a, b = b, a
+	}
+	spec, err := pyver.ParseSpecifier(b)
+	if err != nil {
+		return true
+	}
+	version, err := pyver.Parse(a)
+	return err != nil || !spec.Contains(version)
+}
+
+// Unified renders the result as a unified-diff style text
+func (r *Result) Unified() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", r.A, r.B)
+
+	if r.pythonChanged() {
+		b.WriteString("@@ python @@\n")
+		fmt.Fprintf(&b, "-python %s\n", r.PythonA)
+		fmt.Fprintf(&b, "+python %s\n", r.PythonB)
+	}
+
+	if len(r.Packages) > 0 {
+		b.WriteString("@@ packages @@\n")
+	}
+	for _, c := range r.Packages {
+		if c.Kind != Added {
+			fmt.Fprintf(&b, "-%s\n", formatPackage(c.Name, c.Old))
+		}
+		if c.Kind != Removed {
+			fmt.Fprintf(&b, "+%s\n", formatPackage(c.Name, c.New))
+		}
+	}
+	return b.String()
+}
+
+func formatPackage(name, version string) string {
+	switch {
+	case version == "":
+		return name
+	case strings.Contains(version, "://"):
+		return name + " @ " + version
+	case strings.ContainsAny(version[:1], "<>=!~"):
+		return name + version
+	default:
+		return name + "==" + version
+	}
+}
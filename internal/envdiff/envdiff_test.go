@@ -0,0 +1,128 @@
+package envdiff
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRequirements(t *testing.T) {
+	pkgs := ParseRequirements([]byte(`# comment
+Django==4.2.1
+zope.interface==6.0 ; python_version > "3"
+requests[socks]>=2.0
+-e ./local
+mypkg @ file:///tmp/mypkg
+click
+`))
+
+	expected := map[string]string{
+		"django":         "4.2.1",
+		"zope-interface": "6.0",
+		"requests":       ">=2.0",
+		"mypkg":          "file:///tmp/mypkg",
+		"click":          "",
+	}
+	if len(pkgs) != len(expected) {
+		t.Fatalf("unexpected packages: %v", pkgs)
+	}
+	for name, version := range expected {
+		if pkgs[name] != version {
+			t.Fatalf("expected %s=%q, got %q", name, version, pkgs[name])
+		}
+	}
+}
+
+func TestCompareAndUnified(t *testing.T) {
+	a := &Snapshot{Name: "a", Python: "3.10.14", Packages: map[string]string{"numpy": "1.26.0", "six": "1.16.0", "rich": "13.7.1"}}
+	b := &Snapshot{Name: "b", Python: "3.12.3", Packages: map[string]string{"numpy": "2.0.0", "rich": "13.7.1", "attrs": "23.2.0"}}
+
+	r := Compare(a, b)
+	if len(r.Packages) != 3 {
+		t.Fatalf("unexpected changes: %+v", r.Packages)
+	}
+
+	expected := `--- a
++++ b
+@@ python @@
+-python 3.10.14
++python 3.12.3
+@@ packages @@
++attrs==23.2.0
+-numpy==1.26.0
++numpy==2.0.0
+-six==1.16.0
+`
+	if got := r.Unified(); got != expected {
+		t.Fatalf("unexpected unified output:\n%s", got)
+	}
+}
+
+func TestFromFileUvLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uv.lock")
+	lock := `version = 1
+requires-python = ">=3.11"
+
+[[package]]
+name = "Requests"
+version = "2.32.3"
+`
+	if err := os.WriteFile(path, []byte(lock), 0644); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+
+	s, err := FromFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Python != ">=3.11" || s.Packages["requests"] != "2.32.3" {
+		t.Fatalf("unexpected snapshot: %+v", s)
+	}
+	if !strings.HasSuffix(s.Name, "uv.lock") {
+		t.Fatalf("unexpected name: %s", s.Name)
+	}
+}
+
+func TestComparePythonAgainstFiles(t *testing.T) {
+	dir := t.TempDir()
+	reqPath := filepath.Join(dir, "requirements.txt")
+	lockPath := filepath.Join(dir, "uv.lock")
+	if err := os.WriteFile(reqPath, []byte("requests==2.32.3\n"), 0644); err != nil {
+		t.Fatalf("write requirements: %v", err)
+	}
+	lock := `version = 1
+requires-python = ">=3.11"
+
+[[package]]
+name = "requests"
+version = "2.32.3"
+`
+	if err := os.WriteFile(lockPath, []byte(lock), 0644); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+
+	current := &Snapshot{Name: "env", Python: "3.12.3", Packages: map[string]string{"requests": "2.32.3"}}
+	old := &Snapshot{Name: "old", Python: "3.10.14", Packages: map[string]string{"requests": "2.32.3"}}
+	for _, path := range []string{reqPath, lockPath} {
+		s, err := FromFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if r := Compare(current, s); !r.Empty() {
+			t.Fatalf("expected %s to match the env, got:\n%s", path, r.Unified())
+		}
+		if r := Compare(s, current); !r.Empty() {
+			t.Fatalf("expected the env to match %s, got:\n%s", path, r.Unified())
+		}
+	}
+
+	s, err := FromFile(lockPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := Compare(old, s)
+	if r.Empty() || !strings.Contains(r.Unified(), "-python 3.10.14\n+python >=3.11\n") {
+		t.Fatalf("expected a Python outside the range to be reported, got:\n%s", r.Unified())
+	}
+}
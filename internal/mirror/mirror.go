@@ -1,13 +1,13 @@
 package mirror
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
-	"github.com/BurntSushi/toml"
 	"github.com/uda/uda/internal/config"
 )
 
@@ -33,7 +33,7 @@ func GetMirror() string {
 	}
 
 	// 2. Check config file
-	cfg, err := loadConfig()
+	cfg, err := config.Load()
 	if err == nil && cfg.Mirror != nil && cfg.Mirror.URL != "" {
 		return cfg.Mirror.URL
 	}
@@ -43,10 +43,10 @@ func GetMirror() string {
 }
 
 // FindWorkingMirror finds the first working mirror
-func FindWorkingMirror() (string, error) {
+func FindWorkingMirror(ctx context.Context) (string, error) {
 	// Try mirrors in order
 	for _, m := range defaultMirrors {
-		if testMirror(m.URL) {
+		if testMirror(ctx, m.URL) {
 			return m.URL, nil
 		}
 	}
@@ -54,7 +54,7 @@ func FindWorkingMirror() (string, error) {
 }
 
 // TestMirror tests if a mirror is accessible
-func testMirror(url string) bool {
+func testMirror(ctx context.Context, url string) bool {
 	if !strings.HasSuffix(url, "/") {
 		url += "/"
 	}
@@ -64,41 +64,29 @@ func testMirror(url string) bool {
 		Timeout: 5 * time.Second,
 	}
 
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return false
 	}
-	defer resp.Body.Close()
-
-	return resp.StatusCode == 200
-}
-
-// loadConfig loads configuration from file
-func loadConfig() (*config.Config, error) {
-	var cfg config.Config
-
-	_, err := toml.DecodeFile(config.ConfigPath(), &cfg)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return false
 	}
+	defer resp.Body.Close()
 
-	return &cfg, nil
+	return resp.StatusCode == 200
 }
 
-// SaveMirror saves mirror configuration
+// SaveMirror saves mirror configuration, keeping other settings
 func SaveMirror(url string) error {
-	cfg := &config.Config{
-		Mirror: &config.MirrorConfig{
-			URL:      url,
-			Priority: 0,
-		},
-	}
-
-	file, err := os.Create(config.ConfigPath())
+	cfg, err := config.LoadOrDefault()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	return toml.NewEncoder(file).Encode(cfg)
+	cfg.Mirror = &config.MirrorConfig{
+		URL:      url,
+		Priority: 0,
+	}
+	return config.Save(cfg)
 }
@@ -0,0 +1,110 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Project describes what an environment needs to run a project directory
+type Project struct {
+	Dir              string
+	Name             string
+	Python           string
+	Dependencies     []string
+	RequirementFiles []string
+}
+
+type pyproject struct {
+	Project struct {
+		Name                 string              `toml:"name"`
+		RequiresPython       string              `toml:"requires-python"`
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+}
+
+// Load reads a project directory. pyproject.toml is preferred; without it
+// requirements*.txt files are used. .python-version fills in the Python
+// version when pyproject.toml does not declare requires-python.
+func Load(dir string, extras []string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("project directory %s does not exist", dir)
+	}
+
+	p := &Project{Dir: dir}
+
+	pyprojectPath := filepath.Join(dir, "pyproject.toml")
+	if _, err := os.Stat(pyprojectPath); err == nil {
+		var pp pyproject
+		if _, err := toml.DecodeFile(pyprojectPath, &pp); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", pyprojectPath, err)
+		}
+
+		p.Name = pp.Project.Name
+		p.Python = pp.Project.RequiresPython
+		p.Dependencies = append(p.Dependencies, pp.Project.Dependencies...)
+		for _, extra := range extras {
+			deps, ok := pp.Project.OptionalDependencies[extra]
+			if !ok {
+				return nil, fmt.Errorf("extra %q is not defined in %s", extra, pyprojectPath)
+			}
+			p.Dependencies = append(p.Dependencies, deps...)
+		}
+	} else {
+		if len(extras) > 0 {
+			return nil, fmt.Errorf("extras require a pyproject.toml in %s", dir)
+		}
+		files, err := requirementFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		p.RequirementFiles = files
+	}
+
+	if p.Python == "" {
+		p.Python = readPythonVersion(dir)
+	}
+
+	return p, nil
+}
+
+// requirementFiles returns requirements*.txt in dir, requirements.txt first
+func requirementFiles(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "requirements*.txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		bi, bj := filepath.Base(matches[i]), filepath.Base(matches[j])
+		if bi == "requirements.txt" || bj == "requirements.txt" {
+			return bi == "requirements.txt"
+		}
+		return bi < bj
+	})
+	return matches, nil
+}
+
+func readPythonVersion(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, ".python-version"))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
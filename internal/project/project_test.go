@@ -0,0 +1,70 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestLoadPyprojectWithExtras(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pyproject.toml"), `[project]
+name = "demo"
+requires-python = ">=3.10"
+dependencies = ["requests>=2", "click"]
+
+[project.optional-dependencies]
+test = ["pytest"]
+`)
+
+	p, err := Load(dir, []string{"test"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name != "demo" || p.Python != ">=3.10" {
+		t.Fatalf("unexpected name or python: %q, %q", p.Name, p.Python)
+	}
+	expected := []string{"requests>=2", "click", "pytest"}
+	if !reflect.DeepEqual(p.Dependencies, expected) {
+		t.Fatalf("unexpected dependencies: %v", p.Dependencies)
+	}
+}
+
+func TestLoadRequirementsFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "requirements-dev.txt"), "pytest\n")
+	writeFile(t, filepath.Join(dir, "requirements.txt"), "numpy\n")
+	writeFile(t, filepath.Join(dir, ".python-version"), "# pinned\n3.11\n")
+
+	p, err := Load(dir, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Python != "3.11" {
+		t.Fatalf("unexpected python: %q", p.Python)
+	}
+	expected := []string{
+		filepath.Join(p.Dir, "requirements.txt"),
+		filepath.Join(p.Dir, "requirements-dev.txt"),
+	}
+	if !reflect.DeepEqual(p.RequirementFiles, expected) {
+		t.Fatalf("unexpected requirement files: %v", p.RequirementFiles)
+	}
+}
+
+func TestLoadUnknownExtra(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pyproject.toml"), "[project]\nname = \"demo\"\n")
+
+	if _, err := Load(dir, []string{"docs"}); err == nil {
+		t.Fatalf("expected error for unknown extra")
+	}
+}
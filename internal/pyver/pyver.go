@@ -0,0 +1,189 @@
+package pyver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a release version such as 3.12.4
+type Version struct {
+	Release    []int
+	Prerelease bool
+	raw        string
+}
+
+// Parse parses a version string. Anything after the numeric release
+// segment (rc1, a2, +local...) marks it as a pre-release or variant.
+func Parse(s string) (Version, error) {
+	s = strings.TrimSpace(strings.TrimPrefix(s, "v"))
+	v := Version{raw: s}
+
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.') {
+		end++
+	}
+	release := strings.TrimSuffix(s[:end], ".")
+	if release == "" {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+
+	for _, part := range strings.Split(release, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+		v.Release = append(v.Release, n)
+	}
+	v.Prerelease = end < len(s)
+	return v, nil
+}
+
+func (v Version) String() string {
+	return v.raw
+}
+
+// Compare returns -1, 0 or 1, padding the shorter release with zeros
+func (v Version) Compare(o Version) int {
+	n := len(v.Release)
+	if len(o.Release) > n {
+		n = len(o.Release)
+	}
+	for i := 0; i < n; i++ {
+		a, b := segment(v.Release, i), segment(o.Release, i)
+		if a != b {
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func segment(release []int, i int) int {
+	if i < len(release) {
+		return release[i]
+	}
+	return 0
+}
+
+// hasPrefix reports whether v's release starts with prefix
+func (v Version) hasPrefix(prefix []int) bool {
+	for i, n := range prefix {
+		if segment(v.Release, i) != n {
+			return false
+		}
+	}
+	return true
+}
+
+type clause struct {
+	op       string
+	version  Version
+	wildcard bool
+}
+
+// Specifier is a comma separated list of PEP 440 version clauses
+type Specifier struct {
+	clauses []clause
+	raw     string
+}
+
+var operators = []string{"===", "~=", "==", "!=", "<=", ">=", "<", ">"}
+
+// IsSpecifier reports whether s is a version range rather than a plain version
+func IsSpecifier(s string) bool {
+	return strings.ContainsAny(s, "<>=!~,")
+}
+
+// ParseSpecifier parses a specifier such as ">=3.10,<3.13"
+func ParseSpecifier(s string) (Specifier, error) {
+	spec := Specifier{raw: s}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		op := ""
+		for _, candidate := range operators {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				break
+			}
+		}
+		if op == "" {
+			return spec, fmt.Errorf("invalid version specifier %q", part)
+		}
+
+		value := strings.TrimSpace(strings.TrimPrefix(part, op))
+		c := clause{op: op}
+		if strings.HasSuffix(value, ".*") {
+			if op != "==" && op != "!=" {
+				return spec, fmt.Errorf("wildcard not allowed with %s in %q", op, part)
+			}
+			c.wildcard = true
+			value = strings.TrimSuffix(value, ".*")
+		}
+
+		v, err := Parse(value)
+		if err != nil {
+			return spec, err
+		}
+		if op == "~=" && len(v.Release) < 2 {
+			return spec, fmt.Errorf("~= requires at least two release segments in %q", part)
+		}
+		c.version = v
+		spec.clauses = append(spec.clauses, c)
+	}
+
+	if len(spec.clauses) == 0 {
+		return spec, fmt.Errorf("empty version specifier")
+	}
+	return spec, nil
+}
+
+func (s Specifier) String() string {
+	return s.raw
+}
+
+// Contains reports whether v satisfies every clause of the specifier
+func (s Specifier) Contains(v Version) bool {
+	for _, c := range s.clauses {
+		if !c.contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c clause) contains(v Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case "===":
+		return v.raw == c.version.raw
+	case "==":
+		if c.wildcard {
+			return v.hasPrefix(c.version.Release)
+		}
+		return cmp == 0
+	case "!=":
+		if c.wildcard {
+			return !v.hasPrefix(c.version.Release)
+		}
+		return cmp != 0
+	case "~=":
+		prefix := c.version.Release[:len(c.version.Release)-1]
+		return cmp >= 0 && v.hasPrefix(prefix)
+	case "<=":
+		return cmp <= 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case ">":
+		return cmp > 0
+	}
+	return false
+}
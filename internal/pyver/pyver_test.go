@@ -0,0 +1,55 @@
+package pyver
+
+import "testing"
+
+func TestSpecifierContains(t *testing.T) {
+	cases := []struct {
+		spec    string
+		version string
+		want    bool
+	}{
+		{">=3.10,<3.13", "3.12.7", true},
+		{">=3.10,<3.13", "3.13.0", false},
+		{">=3.10,<3.13", "3.9.18", false},
+		{"==3.11.*", "3.11.9", true},
+		{"==3.11.*", "3.12.0", false},
+		{"!=3.11.*", "3.12.0", true},
+		{"~=3.10", "3.12.1", true},
+		{"~=3.10.2", "3.11.0", false},
+		{"~=3.10.2", "3.10.14", true},
+		{">3.10", "3.10.0", false},
+		{"==3.11", "3.11.0", true},
+	}
+
+	for _, c := range cases {
+		spec, err := ParseSpecifier(c.spec)
+		if err != nil {
+			t.Fatalf("parse %q: %v", c.spec, err)
+		}
+		v, err := Parse(c.version)
+		if err != nil {
+			t.Fatalf("parse %q: %v", c.version, err)
+		}
+		if got := spec.Contains(v); got != c.want {
+			t.Fatalf("%q contains %q: got %v, want %v", c.spec, c.version, got, c.want)
+		}
+	}
+}
+
+func TestParseSpecifierRejectsInvalid(t *testing.T) {
+	for _, s := range []string{"3.11", ">=", "~=3", ">=3.*"} {
+		if _, err := ParseSpecifier(s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+}
+
+func TestParsePrerelease(t *testing.T) {
+	v, err := Parse("3.14.0rc2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !v.Prerelease || v.Compare(Version{Release: []int{3, 14}}) != 0 {
+		t.Fatalf("unexpected version: %+v", v)
+	}
+}
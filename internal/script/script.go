@@ -0,0 +1,155 @@
+// Package script reads PEP 723 inline script metadata and script lock files
+package script
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Metadata is the content of a script's "# /// script" block
+type Metadata struct {
+	RequiresPython string   `toml:"requires-python"`
+	Dependencies   []string `toml:"dependencies"`
+}
+
+// Load reads the metadata of a script file. A script without a block has
+// empty metadata.
+func Load(path string) (*Metadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return meta, nil
+}
+
+// Parse extracts the script metadata block from source. As in the PEP 723
+// reference implementation, a block starts at "# /// <type>", runs over the
+// following "#" and "# ..." lines and ends at the last "# ///" among them.
+func Parse(source string) (*Metadata, error) {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+
+	var block []string
+	found := false
+	for i := 0; i < len(lines); i++ {
+		blockType, ok := strings.CutPrefix(lines[i], "# /// ")
+		if !ok || blockType == "" || strings.ContainsAny(blockType, " \t") {
+			continue
+		}
+
+		end := -1
+		j := i + 1
+		for ; j < len(lines) && (lines[j] == "#" || strings.HasPrefix(lines[j], "# ")); j++ {
+			if lines[j] == "# ///" {
+				end = j
+			}
+		}
+		if end < 0 {
+			// Blocks of other types are not ours to validate
+			if blockType == "script" {
+				return nil, fmt.Errorf("line %d: unclosed script block", i+1)
+			}
+			continue
+		}
+
+		if blockType == "script" {
+			if found {
+				return nil, fmt.Errorf("line %d: multiple script blocks", i+1)
+			}
+			found = true
+			for _, line := range lines[i+1 : end] {
+				line = strings.TrimPrefix(line, "#")
+				block = append(block, strings.TrimPrefix(line, " "))
+			}
+		}
+		i = end
+	}
+
+	var meta Metadata
+	if _, err := toml.Decode(strings.Join(block, "\n"), &meta); err != nil {
+		return nil, fmt.Errorf("invalid script metadata: %w", err)
+	}
+	return &meta, nil
+}
+
+// Hash identifies the Python request and dependencies, independent of
+// their order
+func (m *Metadata) Hash() string {
+	deps := append([]string(nil), m.Dependencies...)
+	sort.Strings(deps)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "requires-python=%s\n", m.RequiresPython)
+	for _, dep := range deps {
+		fmt.Fprintf(h, "dependency=%s\n", dep)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// LockPath returns the lock file written next to a script
+func LockPath(scriptPath string) string {
+	return scriptPath + ".lock"
+}
+
+// lockHeader starts the first line of a lock file, followed by the hash of
+// the metadata it was compiled from
+const lockHeader = "# uda script lock: "
+
+// FormatLock prefixes compiled requirements with the hash of meta
+func FormatLock(meta *Metadata, requirements string) string {
+	return lockHeader + meta.Hash() + "\n# Generated by uda script lock; do not edit\n" + requirements
+}
+
+// Lock is a parsed lock file
+type Lock struct {
+	// Hash of the metadata the lock was compiled from
+	Hash string
+	// Requirements are the pinned requirement lines
+	Requirements []string
+}
+
+// LoadLock reads the lock file of a script; a missing file yields nil
+func LoadLock(scriptPath string) (*Lock, error) {
+	file, err := os.Open(LockPath(scriptPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lock := &Lock{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if hash, ok := strings.CutPrefix(line, lockHeader); ok {
+			lock.Hash = strings.TrimSpace(hash)
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lock.Requirements = append(lock.Requirements, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if lock.Hash == "" {
+		return nil, fmt.Errorf("%s was not written by uda script lock", LockPath(scriptPath))
+	}
+	return lock, nil
+}
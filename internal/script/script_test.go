@@ -0,0 +1,88 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseScriptBlock(t *testing.T) {
+	meta, err := Parse(`#!/usr/bin/env -S uda script run
+# /// script
+# requires-python = ">=3.11"
+# dependencies = [
+#   "requests<3",
+#   "rich",
+# ]
+#
+# [tool.other]
+# note = "# /// inside a string"
+# ///
+
+import requests
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if meta.RequiresPython != ">=3.11" {
+		t.Fatalf("unexpected requires-python: %q", meta.RequiresPython)
+	}
+	if !reflect.DeepEqual(meta.Dependencies, []string{"requests<3", "rich"}) {
+		t.Fatalf("unexpected dependencies: %v", meta.Dependencies)
+	}
+}
+
+func TestParseWithoutBlockAndErrors(t *testing.T) {
+	meta, err := Parse("print('hi')\n")
+	if err != nil || meta.RequiresPython != "" || len(meta.Dependencies) != 0 {
+		t.Fatalf("expected empty metadata, got %+v, %v", meta, err)
+	}
+
+	meta, err = Parse("# /// foo\nprint('hi')\n# /// script\n# dependencies = [\"rich\"]\n# ///\n")
+	if err != nil || !reflect.DeepEqual(meta.Dependencies, []string{"rich"}) {
+		t.Fatalf("expected an unclosed block of another type to be ignored, got %+v, %v", meta, err)
+	}
+
+	cases := map[string]string{
+		"# /// script\n# dependencies = []\nprint()\n":                     "line 1: unclosed script block",
+		"# /// script\n# ///\n\n# /// script\n# ///\n":                     "line 4: multiple script blocks",
+		"# /// script\n# dependencies = [\n# ///\n":                        "invalid script metadata",
+		"# /// other\n# x = 1\n# ///\n# /// script\n# dependencies = []\n": "line 4: unclosed script block",
+	}
+	for input, expected := range cases {
+		if _, err := Parse(input); err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Fatalf("Parse(%q): expected %q, got %v", input, expected, err)
+		}
+	}
+}
+
+func TestLockRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool.py")
+	meta := &Metadata{RequiresPython: ">=3.11", Dependencies: []string{"rich", "requests<3"}}
+	reordered := &Metadata{RequiresPython: ">=3.11", Dependencies: []string{"requests<3", "rich"}}
+	if meta.Hash() != reordered.Hash() {
+		t.Fatal("expected the hash to ignore dependency order")
+	}
+
+	if lock, err := LoadLock(path); lock != nil || err != nil {
+		t.Fatalf("expected no lock, got %+v, %v", lock, err)
+	}
+
+	compiled := "requests==2.32.3\n    # via -r script\nrich==13.9.4 ; python_version >= \"3.11\"\n"
+	if err := os.WriteFile(LockPath(path), []byte(FormatLock(meta, compiled)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lock, err := LoadLock(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lock.Hash != meta.Hash() {
+		t.Fatalf("unexpected hash: %s", lock.Hash)
+	}
+	expected := []string{"requests==2.32.3", `rich==13.9.4 ; python_version >= "3.11"`}
+	if !reflect.DeepEqual(lock.Requirements, expected) {
+		t.Fatalf("unexpected requirements: %q", lock.Requirements)
+	}
+}
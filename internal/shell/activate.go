@@ -0,0 +1,220 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/uda/uda/internal/config"
+	"github.com/uda/uda/internal/env"
+)
+
+// activation is the uda state inherited from the calling shell
+type activation struct {
+	envPath string
+	name    string
+	stack   []string
+	vars    []string
+}
+
+// currentActivation reads the activation state exported by earlier activate scripts
+func currentActivation() activation {
+	a := activation{
+		envPath: os.Getenv("VIRTUAL_ENV"),
+		name:    os.Getenv("_UDA_ACTIVE_ENV"),
+		vars:    trackedVars("_UDA_ENV_VARS"),
+	}
+	if stack := os.Getenv("_UDA_STACK"); stack != "" {
+		a.stack = strings.Split(stack, ":")
+	}
+	return a
+}
+
+// stackable reports whether the active env is a uda env that can be kept underneath a new one
+func (a activation) stackable() bool {
+	return a.envPath != "" && a.name != "" && a.name != "base"
+}
+
+// trackedVars reads a space separated list of variable names set by uda
+func trackedVars(listVar string) []string {
+	var names []string
+	for _, name := range strings.Fields(os.Getenv(listVar)) {
+		if env.ValidateVarName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// promptLabel shows the stacked envs below the active one, e.g. "proj > tools"
+func promptLabel(stack []string, name string) string {
+	return strings.Join(append(append([]string{}, stack...), name), " > ")
+}
+
+// GenerateActivateScript generates activation commands for a specific
+// environment in the given shell's syntax. With stack, the currently active
+// env stays on PATH behind the new one and is restored by the next deactivate.
+func GenerateActivateScript(shellType string, envName string, stack bool) (string, error) {
+	d, err := Lookup(shellType)
+	if err != nil {
+		return "", err
+	}
+	w := d.Script()
+	if err := writeActivate(w, envName, stack); err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+// writeActivate writes the activation of envName to w
+func writeActivate(w Script, envName string, stack bool) error {
+	envPath := config.EnvPath(envName)
+
+	if _, err := os.Stat(envPath); os.IsNotExist(err) {
+		return fmt.Errorf("environment %s does not exist", envName)
+	}
+
+	meta, err := env.LoadMeta(envName)
+	if err != nil {
+		return err
+	}
+
+	cur := currentActivation()
+	var newStack []string
+	if stack && cur.stackable() {
+		// Keep the current env underneath: its bin dir and variables stay in place
+		newStack = append(cur.stack, cur.name)
+		pushVars(w, cur.vars, len(newStack))
+		w.SetVar("_UDA_STACK", strings.Join(newStack, ":"))
+	} else {
+		// The previously active env and any stacked below it are left first
+		leaveAll(w, cur)
+	}
+
+	w.SetVar("VIRTUAL_ENV", envPath)
+	w.SetVar("_UDA_ACTIVE_ENV", envName)
+	w.PrependActiveBin()
+
+	// Save previous values so deactivate can put them back
+	names := meta.SortedVars()
+	for _, name := range names {
+		w.SaveVar(name, "_UDA_OLD_"+name)
+		w.SetVar(name, meta.Vars[name])
+	}
+	if len(names) > 0 {
+		w.SetVar("_UDA_ENV_VARS", strings.Join(names, " "))
+	}
+
+	sourceHooks(w, envPath, env.ActivateHooksDir)
+	w.SetVar("UDA_PROMPT_MODIFIER", LoadPrompt().envModifier(newStack, envName))
+	w.SetPrompt(promptLabel(newStack, envName))
+	return nil
+}
+
+// GenerateDeactivateScript generates deactivation commands in the given
+// shell's syntax. The env being left is the VIRTUAL_ENV inherited from the
+// calling shell; if it was stacked, only that level is popped and the env
+// below becomes active.
+func GenerateDeactivateScript(shellType string) (string, error) {
+	d, err := Lookup(shellType)
+	if err != nil {
+		return "", err
+	}
+	w := d.Script()
+
+	cur := currentActivation()
+
+	sourceHooks(w, cur.envPath, env.DeactivateHooksDir)
+	w.RemoveActiveBin()
+	w.UnsetVar("VIRTUAL_ENV")
+	restoreVars(w, cur.vars)
+
+	if level := len(cur.stack); level > 0 {
+		prev, rest := cur.stack[level-1], cur.stack[:level-1]
+		popVars(w, level)
+		w.SetVar("VIRTUAL_ENV", config.EnvPath(prev))
+		w.SetVar("_UDA_ACTIVE_ENV", prev)
+		if len(rest) > 0 {
+			w.SetVar("_UDA_STACK", strings.Join(rest, ":"))
+		} else {
+			w.UnsetVar("_UDA_STACK")
+		}
+		w.SetVar("UDA_PROMPT_MODIFIER", LoadPrompt().envModifier(rest, prev))
+		w.SetPrompt(promptLabel(rest, prev))
+		return w.String(), nil
+	}
+
+	w.SetVar("_UDA_ACTIVE_ENV", "base")
+	w.SetVar("UDA_PROMPT_MODIFIER", LoadPrompt().BaseModifier())
+	w.SetPrompt("base")
+	return w.String(), nil
+}
+
+// leaveAll deactivates the active env and every env stacked below it, top
+// down, as repeated deactivates would
+func leaveAll(w Script, cur activation) {
+	sourceHooks(w, cur.envPath, env.DeactivateHooksDir)
+	w.RemoveActiveBin()
+	restoreVars(w, cur.vars)
+
+	for level := len(cur.stack); level > 0; level-- {
+		envPath := config.EnvPath(cur.stack[level-1])
+		w.SetVar("VIRTUAL_ENV", envPath)
+		names := trackedVars(fmt.Sprintf("_UDA_ENV_VARS_%d", level))
+		popVars(w, level)
+		sourceHooks(w, envPath, env.DeactivateHooksDir)
+		w.RemoveActiveBin()
+		restoreVars(w, names)
+	}
+	if len(cur.stack) > 0 {
+		w.UnsetVar("_UDA_STACK")
+	}
+}
+
+// restoreVars puts back the values the active env's variables replaced
+func restoreVars(w Script, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	for _, name := range names {
+		w.RestoreVar(name, "_UDA_OLD_"+name)
+	}
+	w.UnsetVar("_UDA_ENV_VARS")
+}
+
+// pushVars moves the active env's saved values to a stack level so the env
+// activated on top can track its own
+func pushVars(w Script, names []string, level int) {
+	if len(names) == 0 {
+		return
+	}
+	w.SetVar(fmt.Sprintf("_UDA_ENV_VARS_%d", level), strings.Join(names, " "))
+	for _, name := range names {
+		w.MoveVar("_UDA_OLD_"+name, fmt.Sprintf("_UDA_OLD_%d_%s", level, name))
+	}
+	w.UnsetVar("_UDA_ENV_VARS")
+}
+
+// popVars is the reverse of pushVars
+func popVars(w Script, level int) {
+	listVar := fmt.Sprintf("_UDA_ENV_VARS_%d", level)
+	names := trackedVars(listVar)
+	if len(names) == 0 {
+		return
+	}
+	w.SetVar("_UDA_ENV_VARS", strings.Join(names, " "))
+	for _, name := range names {
+		w.MoveVar(fmt.Sprintf("_UDA_OLD_%d_%s", level, name), "_UDA_OLD_"+name)
+	}
+	w.UnsetVar(listVar)
+}
+
+// sourceHooks sources an env's hook scripts for the writer's shell in order
+func sourceHooks(w Script, envPath string, dir string) {
+	if envPath == "" || w.HookExt() == "" {
+		return
+	}
+	for _, hook := range env.Hooks(envPath, dir, w.HookExt()) {
+		w.Source(hook)
+	}
+}
@@ -0,0 +1,117 @@
+package shell
+
+import (
+	"fmt"
+)
+
+// bashDialect integrates with bash
+type bashDialect struct{}
+
+func (bashDialect) Name() string { return "bash" }
+
+func (bashDialect) Script() Script { return &posixScript{} }
+
+func (bashDialect) AutoActivate(envName string) string {
+	return fmt.Sprintf(`if [[ $- == *i* ]] && [ -z "${VIRTUAL_ENV-}" ]; then
+    uda activate %s
+fi
+`, shQuote(envName))
+}
+
+func (bashDialect) PipShim() string { return posixPipShim }
+
+func (bashDialect) Init(binaryPath string, prompt Prompt) string {
+	quotedPath := shQuote(binaryPath)
+	return fmt.Sprintf(`_UDA_BIN=%[1]s
+_UDA_ACTIVE_ENV="${_UDA_ACTIVE_ENV-base}"
+if [ -z "${UDA_PROMPT_MODIFIER+x}" ]; then
+    export UDA_PROMPT_MODIFIER=%[2]s
+fi
+_UDA_CHANGE_PROMPT=%[3]s
+_UDA_PROMPT_PREFIX="${_UDA_PROMPT_PREFIX-}"
+
+# Re-apply the prefix before every prompt, replacing the one added last
+# time, so prompts rebuilt by PROMPT_COMMAND (starship and the like) keep
+# it. With promptvars the modifier is referenced rather than inlined.
+_uda_update_prompt() {
+    if [ "$_UDA_CHANGE_PROMPT" != 1 ]; then
+        return
+    fi
+    if [ -n "$_UDA_PROMPT_PREFIX" ] && [ "${PS1#"$_UDA_PROMPT_PREFIX"}" != "$PS1" ]; then
+        PS1="${PS1#"$_UDA_PROMPT_PREFIX"}"
+    fi
+    if shopt -q promptvars; then
+        _UDA_PROMPT_PREFIX='${UDA_PROMPT_MODIFIER}'
+    else
+        _UDA_PROMPT_PREFIX="$UDA_PROMPT_MODIFIER"
+    fi
+    PS1="${_UDA_PROMPT_PREFIX}${PS1}"
+}
+
+_uda_set_prompt() {
+    _uda_update_prompt
+}
+
+case ";${PROMPT_COMMAND-};" in
+    *";_uda_update_prompt;"*) ;;
+    *) PROMPT_COMMAND="${PROMPT_COMMAND:+${PROMPT_COMMAND%%;};}_uda_update_prompt" ;;
+esac
+
+_uda_remove_path_entry() {
+    local entry="$1"
+    if [ -z "$entry" ]; then
+        return
+    fi
+
+    local new_path=""
+    local old_ifs="$IFS"
+    local part
+    IFS=":"
+    for part in $PATH; do
+        if [ "$part" != "$entry" ]; then
+            if [ -z "$new_path" ]; then
+                new_path="$part"
+            else
+                new_path="${new_path}:$part"
+            fi
+        fi
+    done
+    IFS="$old_ifs"
+    PATH="$new_path"
+}
+
+_uda_update_prompt
+
+uda() {
+    if [ $# -eq 0 ]; then
+        "$_UDA_BIN"
+        return
+    fi
+
+    local cmd="$1"
+    shift
+
+    case "$cmd" in
+        activate)
+            eval "$("$_UDA_BIN" activate --shell bash "$@")"
+            ;;
+        deactivate)
+            eval "$("$_UDA_BIN" deactivate --shell bash)"
+            ;;
+        *)
+            "$_UDA_BIN" "$cmd" "$@"
+            ;;
+    esac
+}
+
+# Completion is computed by the binary so it follows the command tree
+_uda_complete() {
+    local IFS=$'\n'
+    COMPREPLY=($("$_UDA_BIN" __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _uda_complete uda
+
+# Alias for conda compatibility
+alias conda=uda
+`, quotedPath, shQuote(prompt.BaseModifier()), changeFlag(prompt))
+}
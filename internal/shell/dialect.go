@@ -0,0 +1,86 @@
+package shell
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dialect is one shell's integration: the init script printed by `uda init`
+// and the syntax of the scripts printed by `uda activate`/`uda deactivate`
+type Dialect interface {
+	// Name is the shell name accepted by --shell
+	Name() string
+	// Init returns the shell integration script
+	Init(binaryPath string, prompt Prompt) string
+	// AutoActivate returns the init script addition that activates envName
+	// in interactive shells started without an active env
+	AutoActivate(envName string) string
+	// PipShim returns the init script addition that routes pip and pip3 to
+	// `uda pip` while a uda env is active, or "" if the shell has none
+	PipShim() string
+	// Script starts an empty activate or deactivate script
+	Script() Script
+}
+
+// Script accumulates the steps of an activate or deactivate script in one
+// shell's syntax
+type Script interface {
+	// HookExt is the extension of the activate.d/deactivate.d scripts this
+	// shell sources, or "" if it cannot source them
+	HookExt() string
+	SetVar(name, value string)
+	UnsetVar(name string)
+	// SaveVar copies name to to if name is set
+	SaveVar(name, to string)
+	// MoveVar moves from to to if from is set
+	MoveVar(from, to string)
+	// RestoreVar moves from back to name, or unsets name if from is unset
+	RestoreVar(name, from string)
+	Source(path string)
+	// RemoveActiveBin drops $VIRTUAL_ENV/bin from PATH
+	RemoveActiveBin()
+	// PrependActiveBin puts $VIRTUAL_ENV/bin in front of PATH
+	PrependActiveBin()
+	// SetPrompt refreshes the prompt after UDA_PROMPT_MODIFIER was set for
+	// the env labelled label
+	SetPrompt(label string)
+	String() string
+}
+
+var dialects = map[string]Dialect{}
+
+// Register makes a dialect available under its name and any aliases
+func Register(d Dialect, aliases ...string) {
+	dialects[d.Name()] = d
+	for _, alias := range aliases {
+		dialects[alias] = d
+	}
+}
+
+func init() {
+	Register(bashDialect{})
+	Register(zshDialect{})
+	Register(fishDialect{})
+	Register(nuDialect{}, "nushell")
+	Register(tcshDialect{}, "csh")
+	Register(xonshDialect{})
+}
+
+// Lookup returns the dialect for a shell name
+func Lookup(shellType string) (Dialect, error) {
+	if d, ok := dialects[shellType]; ok {
+		return d, nil
+	}
+	return nil, fmt.Errorf("unsupported shell %q (supported: %s)", shellType, strings.Join(Names(), ", "))
+}
+
+// Names returns the registered shell names, aliases included
+func Names() []string {
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,153 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fishDialect integrates with fish
+type fishDialect struct{}
+
+func (fishDialect) Name() string { return "fish" }
+
+func (fishDialect) Script() Script { return &fishScript{} }
+
+func (fishDialect) AutoActivate(envName string) string {
+	return fmt.Sprintf(`if status is-interactive; and not set -q VIRTUAL_ENV
+    uda activate %s
+end
+`, fishQuote(envName))
+}
+
+func (fishDialect) PipShim() string {
+	return `# pip and pip3 go through uda while a uda env is active
+function _uda_pip
+    set -l pip $argv[1]
+    set -e argv[1]
+    if set -q VIRTUAL_ENV; and test "$_UDA_ACTIVE_ENV" != base; and contains -- "$argv[1]" install uninstall list freeze show
+        $_UDA_BIN pip $argv
+    else
+        command $pip $argv
+    end
+end
+function pip
+    _uda_pip pip $argv
+end
+function pip3
+    _uda_pip pip3 $argv
+end
+`
+}
+
+func (fishDialect) Init(binaryPath string, prompt Prompt) string {
+	quotedPath := fishQuote(binaryPath)
+	return fmt.Sprintf(`# UDA fish functions
+set -g _UDA_BIN %[1]s
+if not set -q _UDA_ACTIVE_ENV
+    set -g _UDA_ACTIVE_ENV base
+end
+if not set -q UDA_PROMPT_MODIFIER
+    set -gx UDA_PROMPT_MODIFIER %[2]s
+end
+
+# The wrapped prompt reads UDA_PROMPT_MODIFIER, so there is nothing to refresh
+function _uda_set_prompt
+end
+
+# Wrap the current prompt so the modifier is shown in front of it
+if test %[3]s = 1; and functions -q fish_prompt; and not functions -q _uda_original_fish_prompt
+    functions -c fish_prompt _uda_original_fish_prompt
+    function fish_prompt
+        printf '%%s' $UDA_PROMPT_MODIFIER
+        _uda_original_fish_prompt
+    end
+end
+
+function uda
+    if test (count $argv) -eq 0
+        $_UDA_BIN
+        return
+    end
+
+    set cmd $argv[1]
+    set argv $argv[2..-1]
+    switch $cmd
+        case activate
+            $_UDA_BIN activate --shell fish $argv | source
+        case deactivate
+            $_UDA_BIN deactivate --shell fish | source
+        case '*'
+            $_UDA_BIN $cmd $argv
+    end
+end
+
+# Completion is computed by the binary so it follows the command tree
+function _uda_complete
+    set -l candidates ($_UDA_BIN __complete (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)
+    if test (count $candidates) -gt 0
+        printf '%%s\n' $candidates
+    else
+        __fish_complete_path (commandline -ct)
+    end
+end
+complete -c uda -f -a '(_uda_complete)'
+
+alias conda uda
+`, quotedPath, fishQuote(prompt.BaseModifier()), changeFlag(prompt))
+}
+
+// fishScript writes fish syntax
+type fishScript struct {
+	b strings.Builder
+}
+
+func (w *fishScript) HookExt() string { return ".fish" }
+
+func (w *fishScript) SetVar(name, value string) {
+	fmt.Fprintf(&w.b, "set -gx %s %s\n", name, fishQuote(value))
+}
+
+func (w *fishScript) UnsetVar(name string) {
+	fmt.Fprintf(&w.b, "set -e %s\n", name)
+}
+
+func (w *fishScript) SaveVar(name, to string) {
+	fmt.Fprintf(&w.b, "if set -q %[1]s\n    set -gx %[2]s $%[1]s\nend\n", name, to)
+}
+
+func (w *fishScript) MoveVar(from, to string) {
+	fmt.Fprintf(&w.b, "if set -q %[1]s\n    set -gx %[2]s $%[1]s\n    set -e %[1]s\nend\n", from, to)
+}
+
+func (w *fishScript) RestoreVar(name, from string) {
+	fmt.Fprintf(&w.b, "if set -q %[2]s\n    set -gx %[1]s $%[2]s\n    set -e %[2]s\nelse\n    set -e %[1]s\nend\n", name, from)
+}
+
+func (w *fishScript) Source(path string) {
+	fmt.Fprintf(&w.b, "source %s\n", fishQuote(path))
+}
+
+func (w *fishScript) RemoveActiveBin() {
+	w.b.WriteString(`if set -q VIRTUAL_ENV
+    set -gx PATH (string match -v -- "$VIRTUAL_ENV/bin" $PATH)
+end
+`)
+}
+
+func (w *fishScript) PrependActiveBin() {
+	w.b.WriteString("set -gx PATH \"$VIRTUAL_ENV/bin\" $PATH\n")
+}
+
+func (w *fishScript) SetPrompt(label string) {
+	fmt.Fprintf(&w.b, "if functions -q _uda_set_prompt\n    _uda_set_prompt %s\nend\n", fishQuote(label))
+}
+
+func (w *fishScript) String() string {
+	return w.b.String()
+}
+
+// fishQuote single-quotes a value for fish, where \ and ' are escaped inside quotes
+func fishQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
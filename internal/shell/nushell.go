@@ -0,0 +1,111 @@
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// nuDialect integrates with nushell. Nushell cannot eval generated code, so
+// activate/deactivate print the resulting variable changes as JSON, which
+// the wrapper applies with load-env and hide-env. Hook scripts are not
+// supported because nushell can only source files known at parse time.
+type nuDialect struct{}
+
+func (nuDialect) Name() string { return "nu" }
+
+func (nuDialect) Script() Script { return &nuScript{stateScript{state: newEnvState()}} }
+
+func (nuDialect) AutoActivate(envName string) string {
+	return fmt.Sprintf(`if $nu.is-interactive and ('VIRTUAL_ENV' not-in $env) {
+    uda activate %s
+}
+`, nuQuote(envName))
+}
+
+func (nuDialect) PipShim() string {
+	return `# pip and pip3 go through uda while a uda env is active
+def _uda_pip_routed [args] {
+    ('VIRTUAL_ENV' in $env) and (($env._UDA_ACTIVE_ENV? | default 'base') != 'base') and ($args | is-not-empty) and ($args.0 in [install uninstall list freeze show])
+}
+def --wrapped pip [...args] {
+    if (_uda_pip_routed $args) { ^$env._UDA_BIN pip ...$args } else { ^pip ...$args }
+}
+def --wrapped pip3 [...args] {
+    if (_uda_pip_routed $args) { ^$env._UDA_BIN pip ...$args } else { ^pip3 ...$args }
+}
+`
+}
+
+func (nuDialect) Init(binaryPath string, prompt Prompt) string {
+	promptCommand := ""
+	if prompt.Change {
+		promptCommand = `
+let _uda_original_prompt = ($env.PROMPT_COMMAND? | default '')
+$env.PROMPT_COMMAND = {||
+    let base = if ($_uda_original_prompt | describe | str starts-with 'closure') {
+        do $_uda_original_prompt
+    } else {
+        $_uda_original_prompt
+    }
+    [($env.UDA_PROMPT_MODIFIER? | default '') $base] | str join
+}
+`
+	}
+
+	return fmt.Sprintf(`# UDA nushell integration
+$env._UDA_BIN = %s
+if '_UDA_ACTIVE_ENV' not-in $env { $env._UDA_ACTIVE_ENV = 'base' }
+if 'UDA_PROMPT_MODIFIER' not-in $env { $env.UDA_PROMPT_MODIFIER = %s }
+%s
+def --env --wrapped uda [...args] {
+    if ($args | is-empty) {
+        ^$env._UDA_BIN
+        return
+    }
+
+    let cmd = $args.0
+    if $cmd in [activate deactivate] {
+        let changes = (^$env._UDA_BIN $cmd --shell nu ...($args | skip 1) | from json)
+        load-env $changes.set
+        hide-env --ignore-errors ...$changes.hide
+        if 'PATH' in $changes.set {
+            $env.PATH = ($env.PATH | split row (char esep))
+        }
+    } else {
+        ^$env._UDA_BIN ...$args
+    }
+}
+
+alias conda = uda
+`, nuQuote(binaryPath), nuQuote(prompt.BaseModifier()), promptCommand)
+}
+
+// nuScript prints the variable changes as JSON
+type nuScript struct {
+	stateScript
+}
+
+func (w *nuScript) String() string {
+	changes := struct {
+		Set  map[string]string `json:"set"`
+		Hide []string          `json:"hide"`
+	}{Set: map[string]string{}, Hide: []string{}}
+
+	for _, name := range w.state.changed {
+		if value, ok := w.state.get(name); ok {
+			changes.Set[name] = value
+		} else {
+			changes.Hide = append(changes.Hide, name)
+		}
+	}
+
+	data, _ := json.Marshal(changes)
+	return string(data) + "\n"
+}
+
+// nuQuote double-quotes a value for nushell
+func nuQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
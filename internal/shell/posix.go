@@ -0,0 +1,97 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// posixScript writes sh/bash/zsh syntax
+type posixScript struct {
+	b strings.Builder
+}
+
+func (w *posixScript) HookExt() string { return ".sh" }
+
+func (w *posixScript) SetVar(name, value string) {
+	fmt.Fprintf(&w.b, "export %s=%s\n", name, shQuote(value))
+}
+
+func (w *posixScript) UnsetVar(name string) {
+	fmt.Fprintf(&w.b, "unset %s\n", name)
+}
+
+func (w *posixScript) SaveVar(name, to string) {
+	fmt.Fprintf(&w.b, `if [ -n "${%[1]s+x}" ]; then
+    export %[2]s="$%[1]s"
+fi
+`, name, to)
+}
+
+func (w *posixScript) MoveVar(from, to string) {
+	fmt.Fprintf(&w.b, `if [ -n "${%[1]s+x}" ]; then
+    export %[2]s="$%[1]s"
+    unset %[1]s
+fi
+`, from, to)
+}
+
+func (w *posixScript) RestoreVar(name, from string) {
+	fmt.Fprintf(&w.b, `if [ -n "${%[2]s+x}" ]; then
+    export %[1]s="$%[2]s"
+    unset %[2]s
+else
+    unset %[1]s
+fi
+`, name, from)
+}
+
+func (w *posixScript) Source(path string) {
+	fmt.Fprintf(&w.b, ". %s\n", shQuote(path))
+}
+
+func (w *posixScript) RemoveActiveBin() {
+	w.b.WriteString(`if [ -n "$VIRTUAL_ENV" ] && command -v _uda_remove_path_entry >/dev/null 2>&1; then
+    _uda_remove_path_entry "$VIRTUAL_ENV/bin"
+fi
+`)
+}
+
+func (w *posixScript) PrependActiveBin() {
+	w.b.WriteString("export PATH=\"$VIRTUAL_ENV/bin:$PATH\"\n")
+}
+
+func (w *posixScript) SetPrompt(label string) {
+	fmt.Fprintf(&w.b, `if command -v _uda_set_prompt >/dev/null 2>&1; then
+    _uda_set_prompt %s
+fi
+`, shQuote(label))
+}
+
+func (w *posixScript) String() string {
+	return w.b.String()
+}
+
+// posixPipShim makes pip and pip3 shell functions for bash and zsh
+const posixPipShim = `# pip and pip3 go through uda while a uda env is active
+unalias pip pip3 2>/dev/null
+_uda_pip() {
+    local pip="$1"
+    shift
+    if [ -n "${VIRTUAL_ENV-}" ] && [ "${_UDA_ACTIVE_ENV:-base}" != base ]; then
+        case "${1-}" in
+            install|uninstall|list|freeze|show)
+                "$_UDA_BIN" pip "$@"
+                return
+                ;;
+        esac
+    fi
+    command "$pip" "$@"
+}
+pip() { _uda_pip pip "$@"; }
+pip3() { _uda_pip pip3 "$@"; }
+`
+
+// shQuote single-quotes a value for POSIX shells
+func shQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
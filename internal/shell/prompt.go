@@ -0,0 +1,82 @@
+package shell
+
+import (
+	"os"
+	"strings"
+
+	"github.com/uda/uda/internal/config"
+	"github.com/uda/uda/internal/env"
+)
+
+// DefaultPromptFormat is used when the config has no env_prompt
+const DefaultPromptFormat = "({name}) "
+
+// Prompt is how the active env is shown in the shell prompt
+type Prompt struct {
+	// Format is env_prompt; {name} is the env label and {python_version}
+	// the env's Python version
+	Format string
+	// Change is false when the shell prompt is left untouched and only
+	// UDA_PROMPT_MODIFIER is kept up to date
+	Change bool
+}
+
+// LoadPrompt reads env_prompt and changeps1 from the config, falling back
+// to the defaults when the config cannot be read
+func LoadPrompt() Prompt {
+	p := Prompt{Format: DefaultPromptFormat, Change: true}
+	cfg, err := config.LoadOrDefault()
+	if err != nil {
+		return p
+	}
+	if cfg.EnvPrompt != "" {
+		p.Format = cfg.EnvPrompt
+	}
+	if cfg.ChangePS1 != nil {
+		p.Change = *cfg.ChangePS1
+	}
+	return p
+}
+
+// Modifier formats the prompt prefix for an env label
+func (p Prompt) Modifier(label string, pythonVersion string) string {
+	return strings.NewReplacer("{name}", label, "{python_version}", pythonVersion).Replace(p.Format)
+}
+
+// BaseModifier is the prompt prefix when no env is active
+func (p Prompt) BaseModifier() string {
+	return p.Modifier("base", "")
+}
+
+// envModifier is the prompt prefix while envName is active under stack
+func (p Prompt) envModifier(stack []string, envName string) string {
+	version, _ := env.PythonVersion(envName)
+	return p.Modifier(promptLabel(stack, envName), version)
+}
+
+// CurrentModifier returns the prompt prefix of the calling shell. With an
+// empty format that is the exported UDA_PROMPT_MODIFIER, or env_prompt
+// applied to the active env; otherwise format is applied.
+func CurrentModifier(format string) string {
+	p := Prompt{Format: format}
+	if format == "" {
+		if modifier, ok := os.LookupEnv("UDA_PROMPT_MODIFIER"); ok {
+			return modifier
+		}
+		p = LoadPrompt()
+	}
+
+	cur := currentActivation()
+	if cur.name == "" || cur.name == "base" {
+		return p.BaseModifier()
+	}
+	return p.envModifier(cur.stack, cur.name)
+}
+
+// changeFlag renders Change as the 1/0 value the init scripts test
+func changeFlag(p Prompt) string {
+	if p.Change {
+		return "1"
+	}
+	return "0"
+}
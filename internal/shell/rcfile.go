@@ -0,0 +1,123 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Markers of the block init --install writes into rc files
+const (
+	BlockStart = "# >>> uda initialize >>>"
+	BlockEnd   = "# <<< uda initialize <<<"
+)
+
+// RCFile returns the startup file init --install edits for a shell
+func RCFile(shellType string) (string, error) {
+	home := os.Getenv("HOME")
+	switch shellType {
+	case "bash":
+		return filepath.Join(home, ".bashrc"), nil
+	case "zsh":
+		if zdotdir := os.Getenv("ZDOTDIR"); zdotdir != "" {
+			return filepath.Join(zdotdir, ".zshrc"), nil
+		}
+		return filepath.Join(home, ".zshrc"), nil
+	case "fish":
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configHome, "fish", "config.fish"), nil
+	}
+	return "", fmt.Errorf("init --install supports bash, zsh and fish, not %q", shellType)
+}
+
+// InitBlock returns the marked rc file block that loads the integration
+func InitBlock(shellType string, binaryPath string) (string, error) {
+	var line string
+	switch shellType {
+	case "bash", "zsh":
+		line = fmt.Sprintf(`eval "$(%s init --shell %s)"`, shQuote(binaryPath), shellType)
+	case "fish":
+		line = fmt.Sprintf("%s init --shell fish | source", fishQuote(binaryPath))
+	default:
+		return "", fmt.Errorf("init --install supports bash, zsh and fish, not %q", shellType)
+	}
+	return BlockStart + "\n# !! Contents within this block are managed by 'uda init' !!\n" + line + "\n" + BlockEnd + "\n", nil
+}
+
+// InstallBlock writes block into the rc file at path, replacing an existing
+// uda block or appending one. It reports whether the file changed.
+func InstallBlock(path string, block string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	content := string(data)
+
+	var updated string
+	if start, end, ok := findBlock(content); ok {
+		updated = content[:start] + block + content[end:]
+	} else {
+		updated = content
+		if updated != "" && !strings.HasSuffix(updated, "\n") {
+			updated += "\n"
+		}
+		if updated != "" {
+			updated += "\n"
+		}
+		updated += block
+	}
+	if updated == content {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, []byte(updated), 0644)
+}
+
+// RemoveBlock deletes the uda block from the rc file at path. It reports
+// whether the file changed.
+func RemoveBlock(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	content := string(data)
+
+	start, end, ok := findBlock(content)
+	if !ok {
+		return false, nil
+	}
+	before := content[:start]
+	// Drop the blank line InstallBlock put in front of the block
+	if strings.HasSuffix(before, "\n\n") {
+		before = before[:len(before)-1]
+	}
+	return true, os.WriteFile(path, []byte(before+content[end:]), 0644)
+}
+
+// findBlock locates the uda block in content, including the newline after
+// the end marker
+func findBlock(content string) (int, int, bool) {
+	start := strings.Index(content, BlockStart)
+	if start < 0 {
+		return 0, 0, false
+	}
+	n := strings.Index(content[start:], BlockEnd)
+	if n < 0 {
+		return 0, 0, false
+	}
+	end := start + n + len(BlockEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return start, end, true
+}
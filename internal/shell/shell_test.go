@@ -7,16 +7,37 @@ import (
 	"testing"
 
 	"github.com/uda/uda/internal/config"
+	"github.com/uda/uda/internal/env"
 )
 
-func TestInitBashContainsPipInstallRouting(t *testing.T) {
-	script := Init("bash", "/tmp/uda-bin")
-	if !strings.Contains(script, "uda install") {
-		t.Fatalf("expected bash init script to contain uda install routing")
+func TestPipShimRoutesSubcommandsThroughUda(t *testing.T) {
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+	script, err := Init("bash", "/tmp/uda-bin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
 	if !strings.Contains(script, `case "$cmd" in`) {
 		t.Fatalf("expected bash init case statement")
 	}
+	if strings.Contains(script, "pip") {
+		t.Fatalf("expected pip to be left alone unless pip_shim is enabled")
+	}
+
+	shim, err := PipShim("bash")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"install|uninstall|list|freeze|show)",
+		`"$_UDA_BIN" pip "$@"`,
+		"pip() { _uda_pip pip \"$@\"; }",
+	} {
+		if !strings.Contains(shim, expected) {
+			t.Fatalf("expected pip shim to contain %q, got: %s", expected, shim)
+		}
+	}
 }
 
 func TestGenerateActivateScriptRemovesCurrentEnvWhenPresent(t *testing.T) {
@@ -33,7 +54,7 @@ func TestGenerateActivateScriptRemovesCurrentEnvWhenPresent(t *testing.T) {
 		t.Fatalf("prepare env path: %v", err)
 	}
 	defer os.RemoveAll(config.EnvsPath())
-	script, err := GenerateActivateScript(envName)
+	script, err := GenerateActivateScript("bash", envName, false)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -42,10 +63,442 @@ func TestGenerateActivateScriptRemovesCurrentEnvWhenPresent(t *testing.T) {
 	if !strings.Contains(script, expected) {
 		t.Fatalf("expected pre-cleanup guard, got: %s", script)
 	}
-	if !strings.Contains(script, `export _UDA_ACTIVE_ENV="`+envName+`"`) {
+	if !strings.Contains(script, `export _UDA_ACTIVE_ENV='`+envName+`'`) {
 		t.Fatalf("expected active env export")
 	}
-	if !strings.Contains(script, `export VIRTUAL_ENV="`+envPath+`"`) {
+	if !strings.Contains(script, `export VIRTUAL_ENV='`+envPath+`'`) {
 		t.Fatalf("expected virtual env export")
 	}
 }
+
+func TestGenerateActivateScriptExportsEnvVars(t *testing.T) {
+	envName := "varsenv"
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+
+	if err := os.MkdirAll(config.EnvPath(envName), 0755); err != nil {
+		t.Fatalf("prepare env path: %v", err)
+	}
+	meta := &env.Meta{Vars: map[string]string{"CUDA_VISIBLE_DEVICES": "0", "API_URL": "it's"}}
+	if err := env.SaveMeta(envName, meta); err != nil {
+		t.Fatalf("save meta: %v", err)
+	}
+
+	script, err := GenerateActivateScript("bash", envName, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(script, `export _UDA_OLD_CUDA_VISIBLE_DEVICES="$CUDA_VISIBLE_DEVICES"`) {
+		t.Fatalf("expected previous value to be saved, got: %s", script)
+	}
+	if !strings.Contains(script, `export API_URL='it'\''s'`) {
+		t.Fatalf("expected quoted value export, got: %s", script)
+	}
+	if !strings.Contains(script, `export _UDA_ENV_VARS='API_URL CUDA_VISIBLE_DEVICES'`) {
+		t.Fatalf("expected tracked variable list, got: %s", script)
+	}
+	t.Setenv("_UDA_ENV_VARS", "API_URL CUDA_VISIBLE_DEVICES")
+	deactivate, err := GenerateDeactivateScript("bash")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(deactivate, "    unset CUDA_VISIBLE_DEVICES\n") {
+		t.Fatalf("expected deactivate to unset tracked variables")
+	}
+}
+
+func TestGenerateActivateScriptSourcesHooksInOrder(t *testing.T) {
+	envName := "hookenv"
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+	t.Setenv("VIRTUAL_ENV", "")
+
+	hookDir := filepath.Join(config.EnvPath(envName), env.ActivateHooksDir)
+	if err := os.MkdirAll(hookDir, 0755); err != nil {
+		t.Fatalf("prepare hook dir: %v", err)
+	}
+	for _, name := range []string{"20-b.sh", "10-a.sh", "10-a.fish"} {
+		if err := os.WriteFile(filepath.Join(hookDir, name), []byte("true\n"), 0644); err != nil {
+			t.Fatalf("write hook: %v", err)
+		}
+	}
+
+	script, err := GenerateActivateScript("bash", envName, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first := strings.Index(script, ". '"+filepath.Join(hookDir, "10-a.sh")+"'")
+	second := strings.Index(script, ". '"+filepath.Join(hookDir, "20-b.sh")+"'")
+	if first < 0 || second < first {
+		t.Fatalf("expected hooks sourced in order, got: %s", script)
+	}
+	if strings.Contains(script, "10-a.fish") {
+		t.Fatalf("expected fish hooks to be skipped for POSIX shells")
+	}
+}
+
+func TestDeactivateWithoutEnvIgnoresHooksInWorkingDir(t *testing.T) {
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, env.DeactivateHooksDir), 0755); err != nil {
+		t.Fatalf("prepare hook dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, env.DeactivateHooksDir, "evil.sh"), []byte("true\n"), 0644); err != nil {
+		t.Fatalf("write hook: %v", err)
+	}
+	oldDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer os.Chdir(oldDir)
+	t.Setenv("VIRTUAL_ENV", "")
+	t.Setenv("_UDA_STACK", "")
+
+	script, err := GenerateDeactivateScript("bash")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(script, "evil.sh") {
+		t.Fatalf("expected no hooks without an active env, got: %s", script)
+	}
+}
+
+func TestStackedActivationPushesAndPopsOneLevel(t *testing.T) {
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+
+	for _, name := range []string{"proj", "tools"} {
+		if err := os.MkdirAll(config.EnvPath(name), 0755); err != nil {
+			t.Fatalf("prepare env path: %v", err)
+		}
+	}
+	t.Setenv("VIRTUAL_ENV", config.EnvPath("proj"))
+	t.Setenv("_UDA_ACTIVE_ENV", "proj")
+	t.Setenv("_UDA_ENV_VARS", "FOO")
+	t.Setenv("_UDA_STACK", "")
+
+	script, err := GenerateActivateScript("bash", "tools", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(script, "_uda_remove_path_entry") {
+		t.Fatalf("expected stacked activation to keep the previous bin dir, got: %s", script)
+	}
+	if !strings.Contains(script, "export _UDA_STACK='proj'") || !strings.Contains(script, "export _UDA_ENV_VARS_1='FOO'") {
+		t.Fatalf("expected previous env to be pushed, got: %s", script)
+	}
+	if !strings.Contains(script, "_uda_set_prompt 'proj > tools'") {
+		t.Fatalf("expected stacked prompt, got: %s", script)
+	}
+
+	t.Setenv("VIRTUAL_ENV", config.EnvPath("tools"))
+	t.Setenv("_UDA_ACTIVE_ENV", "tools")
+	t.Setenv("_UDA_ENV_VARS", "")
+	t.Setenv("_UDA_ENV_VARS_1", "FOO")
+	t.Setenv("_UDA_STACK", "proj")
+
+	script, err = GenerateDeactivateScript("bash")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(script, `export VIRTUAL_ENV='`+config.EnvPath("proj")+`'`) {
+		t.Fatalf("expected deactivate to return to the env below, got: %s", script)
+	}
+	if !strings.Contains(script, "unset _UDA_STACK") || !strings.Contains(script, "export _UDA_ENV_VARS='FOO'") {
+		t.Fatalf("expected one level to be popped, got: %s", script)
+	}
+}
+
+func TestActivateWithoutStackLeavesWholeStack(t *testing.T) {
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+
+	for _, name := range []string{"proj", "tools"} {
+		if err := os.MkdirAll(config.EnvPath(name), 0755); err != nil {
+			t.Fatalf("prepare env path: %v", err)
+		}
+	}
+	t.Setenv("VIRTUAL_ENV", config.EnvPath("tools"))
+	t.Setenv("_UDA_ACTIVE_ENV", "tools")
+	t.Setenv("_UDA_ENV_VARS", "")
+	t.Setenv("_UDA_ENV_VARS_1", "FOO")
+	t.Setenv("_UDA_STACK", "proj")
+
+	script, err := GenerateActivateScript("bash", "proj", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"export VIRTUAL_ENV='" + config.EnvPath("proj") + "'\n",
+		"export _UDA_OLD_FOO=\"$_UDA_OLD_1_FOO\"",
+		"export FOO=\"$_UDA_OLD_FOO\"",
+		"unset _UDA_STACK",
+		"_uda_set_prompt 'proj'\n",
+	} {
+		if !strings.Contains(script, expected) {
+			t.Fatalf("expected activate to leave the stack with %q, got: %s", expected, script)
+		}
+	}
+	if strings.Count(script, "_uda_remove_path_entry \"$VIRTUAL_ENV/bin\"") != 2 {
+		t.Fatalf("expected both stacked bin dirs to be removed, got: %s", script)
+	}
+}
+
+func TestInitZshUsesPromptHooksAndCompletion(t *testing.T) {
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+	script, err := Init("zsh", "/tmp/uda-bin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(script, "PS1") {
+		t.Fatalf("expected zsh init to leave PS1 alone")
+	}
+	for _, expected := range []string{
+		"add-zsh-hook precmd _uda_precmd",
+		"add-zsh-hook chpwd _uda_chpwd",
+		"compdef _uda uda",
+		`PROMPT="${_UDA_PROMPT_PREFIX}${PROMPT}"`,
+	} {
+		if !strings.Contains(script, expected) {
+			t.Fatalf("expected zsh init to contain %q", expected)
+		}
+	}
+}
+
+func TestGenerateActivateScriptQuotesForShell(t *testing.T) {
+	envName := "it's env"
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), "my home", ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+	t.Setenv("VIRTUAL_ENV", "")
+
+	if err := os.MkdirAll(config.EnvPath(envName), 0755); err != nil {
+		t.Fatalf("prepare env path: %v", err)
+	}
+
+	bash, err := GenerateActivateScript("bash", envName, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(bash, "export _UDA_ACTIVE_ENV='it'\\''s env'") {
+		t.Fatalf("expected POSIX quoting, got: %s", bash)
+	}
+
+	fish, err := GenerateActivateScript("fish", envName, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(fish, "set -gx _UDA_ACTIVE_ENV 'it\\'s env'") || strings.Contains(fish, "export ") {
+		t.Fatalf("expected fish syntax, got: %s", fish)
+	}
+
+	if _, err := GenerateActivateScript("cmd.exe", envName, false); err == nil {
+		t.Fatalf("expected error for unsupported shell")
+	}
+}
+
+func TestDialectsForOtherShells(t *testing.T) {
+	envName := "dialects"
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+	t.Setenv("VIRTUAL_ENV", "")
+	t.Setenv("PATH", "/usr/bin")
+
+	if err := os.MkdirAll(config.EnvPath(envName), 0755); err != nil {
+		t.Fatalf("prepare env path: %v", err)
+	}
+
+	tcsh, err := GenerateActivateScript("tcsh", envName, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	binDir := filepath.Join(config.EnvPath(envName), "bin")
+	if !strings.Contains(tcsh, "setenv PATH '"+binDir+":/usr/bin';") {
+		t.Fatalf("expected tcsh PATH update, got: %s", tcsh)
+	}
+
+	nu, err := GenerateActivateScript("nu", envName, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(nu, `"_UDA_ACTIVE_ENV":"dialects"`) || !strings.Contains(nu, `"hide":[]`) {
+		t.Fatalf("expected nushell JSON changes, got: %s", nu)
+	}
+
+	xonsh, err := GenerateActivateScript("xonsh", envName, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(xonsh, `${...}["_UDA_ACTIVE_ENV"] = "dialects"`) {
+		t.Fatalf("expected xonsh syntax, got: %s", xonsh)
+	}
+
+	if _, err := Init("powershell", "/tmp/uda-bin"); err == nil {
+		t.Fatalf("expected error for unsupported shell")
+	}
+}
+
+func TestInitRegistersCompletion(t *testing.T) {
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+	expected := map[string]string{
+		"bash": "complete -o default -F _uda_complete uda",
+		"zsh":  "compdef _uda uda",
+		"fish": "complete -c uda -f -a '(_uda_complete)'",
+	}
+	for shellType, registration := range expected {
+		script, err := Init(shellType, "/tmp/uda-bin")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(script, registration) || !strings.Contains(script, "__complete") {
+			t.Fatalf("expected %s init to complete through uda __complete, got: %s", shellType, script)
+		}
+	}
+}
+
+func TestSubshellSetsEnvironmentAndPrompt(t *testing.T) {
+	envName := "subshell"
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+	t.Setenv("VIRTUAL_ENV", "")
+	t.Setenv("PATH", "/usr/bin")
+
+	if err := os.MkdirAll(config.EnvPath(envName), 0755); err != nil {
+		t.Fatalf("prepare env path: %v", err)
+	}
+	if err := env.SaveMeta(envName, &env.Meta{Vars: map[string]string{"FOO": "bar"}}); err != nil {
+		t.Fatalf("save meta: %v", err)
+	}
+
+	cmd, cleanup, err := Subshell("/bin/bash", envName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	environ := strings.Join(cmd.Env, "\n")
+	for _, expected := range []string{
+		"VIRTUAL_ENV=" + config.EnvPath(envName),
+		"PATH=" + filepath.Join(config.EnvPath(envName), "bin") + ":/usr/bin",
+		"FOO=bar",
+	} {
+		if !strings.Contains(environ, expected) {
+			t.Fatalf("expected %q in subshell environment, got: %s", expected, environ)
+		}
+	}
+	if len(cmd.Args) != 4 || cmd.Args[1] != "--rcfile" {
+		t.Fatalf("expected bash to start with an rc file, got: %v", cmd.Args)
+	}
+	rc, err := os.ReadFile(cmd.Args[2])
+	if err != nil {
+		t.Fatalf("read rc file: %v", err)
+	}
+	if !strings.Contains(string(rc), "PS1='${UDA_PROMPT_MODIFIER}'") {
+		t.Fatalf("expected rc file to prefix the prompt, got: %s", rc)
+	}
+}
+
+func TestInstallBlockIsIdempotentAndReversible(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bashrc")
+	original := "alias ll='ls -l'\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("write rc file: %v", err)
+	}
+
+	block, err := InitBlock("bash", "/opt/uda bin/uda")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, expected := range []bool{true, false} {
+		changed, err := InstallBlock(path, block)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if changed != expected {
+			t.Fatalf("install %d: expected changed=%v", i+1, expected)
+		}
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Count(string(data), BlockStart) != 1 || !strings.Contains(string(data), `eval "$('/opt/uda bin/uda' init --shell bash)"`) {
+		t.Fatalf("expected a single init block, got: %s", data)
+	}
+
+	if changed, err := RemoveBlock(path); err != nil || !changed {
+		t.Fatalf("expected block to be removed, changed=%v err=%v", changed, err)
+	}
+	data, _ = os.ReadFile(path)
+	if string(data) != original {
+		t.Fatalf("expected rc file to be restored, got: %q", data)
+	}
+}
+
+func TestAutoActivateSkipsNonInteractiveAndActiveShells(t *testing.T) {
+	script, err := AutoActivate("bash", "my env")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(script, `[[ $- == *i* ]] && [ -z "${VIRTUAL_ENV-}" ]`) || !strings.Contains(script, "uda activate 'my env'") {
+		t.Fatalf("unexpected bash auto-activation: %s", script)
+	}
+
+	fish, err := AutoActivate("fish", "my env")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(fish, "status is-interactive; and not set -q VIRTUAL_ENV") {
+		t.Fatalf("unexpected fish auto-activation: %s", fish)
+	}
+}
+
+func TestEnvPromptFormatAndChangePS1(t *testing.T) {
+	envName := "fmt"
+	oldHomeDir := config.HomeDir
+	config.HomeDir = filepath.Join(t.TempDir(), ".uda")
+	defer func() { config.HomeDir = oldHomeDir }()
+	t.Setenv("VIRTUAL_ENV", "")
+
+	if err := os.MkdirAll(config.EnvPath(envName), 0755); err != nil {
+		t.Fatalf("prepare env path: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(config.EnvPath(envName), "pyvenv.cfg"), []byte("version_info = 3.12.1\n"), 0644); err != nil {
+		t.Fatalf("write pyvenv.cfg: %v", err)
+	}
+	keep := false
+	if err := config.Save(&config.Config{EnvPrompt: "({name}|py{python_version}) ", ChangePS1: &keep}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	script, err := GenerateActivateScript("bash", envName, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(script, "export UDA_PROMPT_MODIFIER='(fmt|py3.12.1) '") {
+		t.Fatalf("expected formatted prompt modifier, got: %s", script)
+	}
+
+	init, err := Init("bash", "/tmp/uda-bin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(init, "_UDA_CHANGE_PROMPT=0") {
+		t.Fatalf("expected init to leave the prompt untouched")
+	}
+}
@@ -0,0 +1,146 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// envState tracks the environment an activate script runs in, starting from
+// the environment uda inherited from the calling shell. Shells whose scripts
+// cannot branch at run time (tcsh evals one line, nushell applies changes
+// with load-env) resolve SaveVar, MoveVar and RestoreVar against it instead.
+type envState struct {
+	vars    map[string]string
+	changed []string
+}
+
+func newEnvState() *envState {
+	s := &envState{vars: make(map[string]string)}
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			s.vars[name] = value
+		}
+	}
+	return s
+}
+
+func (s *envState) get(name string) (string, bool) {
+	value, ok := s.vars[name]
+	return value, ok
+}
+
+func (s *envState) set(name, value string) {
+	s.vars[name] = value
+	s.touch(name)
+}
+
+func (s *envState) unset(name string) {
+	delete(s.vars, name)
+	s.touch(name)
+}
+
+// touch records name as changed, keeping the order of first change
+func (s *envState) touch(name string) {
+	for _, changed := range s.changed {
+		if changed == name {
+			return
+		}
+	}
+	s.changed = append(s.changed, name)
+}
+
+// pathWithoutActiveBin returns PATH without $VIRTUAL_ENV/bin, and false if no env is active
+func (s *envState) pathWithoutActiveBin() (string, bool) {
+	venv, ok := s.get("VIRTUAL_ENV")
+	if !ok || venv == "" {
+		return "", false
+	}
+
+	bin := filepath.Join(venv, "bin")
+	var kept []string
+	for _, entry := range filepath.SplitList(s.vars["PATH"]) {
+		if entry != bin {
+			kept = append(kept, entry)
+		}
+	}
+	return strings.Join(kept, string(os.PathListSeparator)), true
+}
+
+// pathWithActiveBin returns PATH with $VIRTUAL_ENV/bin in front
+func (s *envState) pathWithActiveBin() string {
+	bin := filepath.Join(s.vars["VIRTUAL_ENV"], "bin")
+	if path := s.vars["PATH"]; path != "" {
+		return bin + string(os.PathListSeparator) + path
+	}
+	return bin
+}
+
+// stateScript applies a script's changes to an envState instead of writing
+// shell code. Hooks are not sourced, and the prompt is left to whatever
+// reads UDA_PROMPT_MODIFIER.
+type stateScript struct {
+	state *envState
+}
+
+func (w *stateScript) HookExt() string { return "" }
+
+func (w *stateScript) SetVar(name, value string) {
+	w.state.set(name, value)
+}
+
+func (w *stateScript) UnsetVar(name string) {
+	w.state.unset(name)
+}
+
+func (w *stateScript) SaveVar(name, to string) {
+	if value, ok := w.state.get(name); ok {
+		w.state.set(to, value)
+	}
+}
+
+func (w *stateScript) MoveVar(from, to string) {
+	if value, ok := w.state.get(from); ok {
+		w.state.set(to, value)
+		w.state.unset(from)
+	}
+}
+
+func (w *stateScript) RestoreVar(name, from string) {
+	if value, ok := w.state.get(from); ok {
+		w.state.set(name, value)
+		w.state.unset(from)
+		return
+	}
+	w.state.unset(name)
+}
+
+func (w *stateScript) Source(path string) {}
+
+func (w *stateScript) RemoveActiveBin() {
+	if path, ok := w.state.pathWithoutActiveBin(); ok {
+		w.state.set("PATH", path)
+	}
+}
+
+func (w *stateScript) PrependActiveBin() {
+	w.state.set("PATH", w.state.pathWithActiveBin())
+}
+
+func (w *stateScript) SetPrompt(label string) {}
+
+// String renders the resulting environment as NAME=value lines
+func (w *stateScript) String() string {
+	return strings.Join(w.state.environ(), "\n")
+}
+
+// environ returns the state in the form of os.Environ
+func (s *envState) environ() []string {
+	environ := make([]string, 0, len(s.vars))
+	for name, value := range s.vars {
+		environ = append(environ, name+"="+value)
+	}
+	sort.Strings(environ)
+	return environ
+}
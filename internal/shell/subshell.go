@@ -0,0 +1,132 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/uda/uda/internal/config"
+	"github.com/uda/uda/internal/env"
+)
+
+// Subshell prepares shellPath to run as an interactive child with envName
+// activated. The environment is computed up front so any shell gets
+// VIRTUAL_ENV, PATH and the env's variables; bash, zsh and fish also get a
+// startup file that loads the user's own rc file, sources the env's hooks
+// and prefixes the prompt. The returned cleanup removes those files once
+// the shell has exited.
+func Subshell(shellPath string, envName string) (*exec.Cmd, func(), error) {
+	w := &stateScript{state: newEnvState()}
+	if err := writeActivate(w, envName, false); err != nil {
+		return nil, nil, err
+	}
+	change := LoadPrompt().Change
+
+	cmd := exec.Command(shellPath)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cleanup := func() {}
+
+	shellType := filepath.Base(shellPath)
+	switch shellType {
+	case "bash", "zsh", "fish":
+		dir, err := os.MkdirTemp("", "uda-shell-")
+		if err != nil {
+			return nil, nil, err
+		}
+		cleanup = func() { os.RemoveAll(dir) }
+
+		d, _ := Lookup(shellType)
+		hooks := d.Script()
+		sourceHooks(hooks, config.EnvPath(envName), env.ActivateHooksDir)
+
+		switch shellType {
+		case "bash":
+			rc := filepath.Join(dir, "bashrc")
+			err = os.WriteFile(rc, []byte(bashSubshellRC(hooks.String(), change)), 0644)
+			cmd.Args = append(cmd.Args, "--rcfile", rc, "-i")
+		case "zsh":
+			err = writeZshSubshellRC(dir, hooks.String(), change)
+			w.state.set("ZDOTDIR", dir)
+		case "fish":
+			rc := filepath.Join(dir, "config.fish")
+			err = os.WriteFile(rc, []byte(fishSubshellRC(hooks.String(), change)), 0644)
+			cmd.Args = append(cmd.Args, "-i", "-C", "source "+fishQuote(rc))
+		}
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to write shell startup file: %w", err)
+		}
+	default:
+		// Without a startup file the prompt can only be passed down for
+		// shells that read PS1 from the environment
+		if change {
+			modifier, _ := w.state.get("UDA_PROMPT_MODIFIER")
+			ps1, ok := w.state.get("PS1")
+			if !ok {
+				ps1 = "$ "
+			}
+			w.state.set("PS1", modifier+ps1)
+		}
+	}
+
+	cmd.Env = w.state.environ()
+	return cmd, cleanup, nil
+}
+
+func bashSubshellRC(hooks string, changePrompt bool) string {
+	rc := "[ -f ~/.bashrc ] && . ~/.bashrc\n" + hooks
+	if changePrompt {
+		rc += `if ! declare -F _uda_update_prompt >/dev/null; then
+    PS1='${UDA_PROMPT_MODIFIER}'"$PS1"
+fi
+`
+	}
+	return rc
+}
+
+// writeZshSubshellRC points ZDOTDIR at dir for the startup files that load
+// the user's own and then restore ZDOTDIR
+func writeZshSubshellRC(dir, hooks string, changePrompt bool) error {
+	restore := "unset ZDOTDIR"
+	if zdotdir, ok := os.LookupEnv("ZDOTDIR"); ok {
+		restore = "ZDOTDIR=" + shQuote(zdotdir)
+	}
+
+	zshenv := fmt.Sprintf(`%s
+[ -f "${ZDOTDIR:-$HOME}/.zshenv" ] && . "${ZDOTDIR:-$HOME}/.zshenv"
+ZDOTDIR=%s
+`, restore, shQuote(dir))
+
+	zshrc := fmt.Sprintf(`%s
+[ -f "${ZDOTDIR:-$HOME}/.zshrc" ] && . "${ZDOTDIR:-$HOME}/.zshrc"
+%s`, restore, hooks)
+	if changePrompt {
+		zshrc += `if (( ! $+functions[_uda_update_prompt] )); then
+    PROMPT="${UDA_PROMPT_MODIFIER//\%/%%}${PROMPT}"
+fi
+`
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".zshenv"), []byte(zshenv), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ".zshrc"), []byte(zshrc), 0644)
+}
+
+func fishSubshellRC(hooks string, changePrompt bool) string {
+	rc := hooks
+	if changePrompt {
+		rc += `if not functions -q _uda_original_fish_prompt; and functions -q fish_prompt
+    functions -c fish_prompt _uda_original_fish_prompt
+    function fish_prompt
+        printf '%s' $UDA_PROMPT_MODIFIER
+        _uda_original_fish_prompt
+    end
+end
+`
+	}
+	return rc
+}
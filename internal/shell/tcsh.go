@@ -0,0 +1,113 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tcshDialect integrates with tcsh and csh. The wrapper evals the output of
+// activate/deactivate as one line, so every step is a single ;-terminated
+// command and conditions are resolved up front against envState.
+type tcshDialect struct{}
+
+func (tcshDialect) Name() string { return "tcsh" }
+
+func (tcshDialect) Script() Script { return &tcshScript{state: newEnvState()} }
+
+func (tcshDialect) AutoActivate(envName string) string {
+	return fmt.Sprintf(`if ($?prompt && ! $?VIRTUAL_ENV) then
+    uda activate %s
+endif
+`, cshQuote(envName))
+}
+
+// PipShim is not offered for tcsh: an alias cannot fall back to the real
+// pip without a separate wrapper script
+func (tcshDialect) PipShim() string { return "" }
+
+func (tcshDialect) Init(binaryPath string, prompt Prompt) string {
+	// Without _UDA_BASE_PROMPT activation scripts leave the prompt alone
+	basePrompt := ""
+	if prompt.Change {
+		basePrompt = "if ($?prompt && ! $?_UDA_BASE_PROMPT) set _UDA_BASE_PROMPT = \"$prompt\"\n"
+	}
+
+	return fmt.Sprintf(`# UDA tcsh integration
+set _uda_bin = %s
+if (! $?_UDA_ACTIVE_ENV) setenv _UDA_ACTIVE_ENV base
+if (! $?UDA_PROMPT_MODIFIER) setenv UDA_PROMPT_MODIFIER %s
+%salias uda 'set _uda_args = (\!*); if ($#_uda_args == 0) set _uda_args = (--help); if ("$_uda_args[1]" == activate || "$_uda_args[1]" == deactivate) eval "`+"`"+`$_uda_bin:q $_uda_args[1] --shell tcsh $_uda_args[2-]:q`+"`"+`"; if ("$_uda_args[1]" != activate && "$_uda_args[1]" != deactivate) $_uda_bin:q $_uda_args:q'
+alias conda uda
+`, cshQuote(binaryPath), cshQuote(prompt.BaseModifier()), basePrompt)
+}
+
+// tcshScript writes tcsh syntax
+type tcshScript struct {
+	state *envState
+	b     strings.Builder
+}
+
+func (w *tcshScript) HookExt() string { return ".csh" }
+
+func (w *tcshScript) SetVar(name, value string) {
+	w.state.set(name, value)
+	fmt.Fprintf(&w.b, "setenv %s %s;\n", name, cshQuote(value))
+}
+
+func (w *tcshScript) UnsetVar(name string) {
+	w.state.unset(name)
+	fmt.Fprintf(&w.b, "unsetenv %s;\n", name)
+}
+
+func (w *tcshScript) SaveVar(name, to string) {
+	if value, ok := w.state.get(name); ok {
+		w.SetVar(to, value)
+	}
+}
+
+func (w *tcshScript) MoveVar(from, to string) {
+	if value, ok := w.state.get(from); ok {
+		w.SetVar(to, value)
+		w.UnsetVar(from)
+	}
+}
+
+func (w *tcshScript) RestoreVar(name, from string) {
+	if value, ok := w.state.get(from); ok {
+		w.SetVar(name, value)
+		w.UnsetVar(from)
+		return
+	}
+	w.UnsetVar(name)
+}
+
+func (w *tcshScript) Source(path string) {
+	fmt.Fprintf(&w.b, "source %s;\n", cshQuote(path))
+}
+
+func (w *tcshScript) RemoveActiveBin() {
+	if path, ok := w.state.pathWithoutActiveBin(); ok {
+		w.SetVar("PATH", path)
+	}
+}
+
+func (w *tcshScript) PrependActiveBin() {
+	w.SetVar("PATH", w.state.pathWithActiveBin())
+}
+
+func (w *tcshScript) SetPrompt(label string) {
+	modifier, _ := w.state.get("UDA_PROMPT_MODIFIER")
+	modifier = strings.ReplaceAll(modifier, "%", "%%")
+	fmt.Fprintf(&w.b, "if ($?_UDA_BASE_PROMPT) set prompt = %s\"$_UDA_BASE_PROMPT\";\n", cshQuote(modifier))
+}
+
+func (w *tcshScript) String() string {
+	return w.b.String()
+}
+
+// cshQuote single-quotes a value for csh, where ! triggers history
+// substitution even inside quotes
+func cshQuote(value string) string {
+	value = strings.ReplaceAll(value, "'", `'\''`)
+	return "'" + strings.ReplaceAll(value, "!", `\!`) + "'"
+}
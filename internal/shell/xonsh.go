@@ -0,0 +1,121 @@
+package shell
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// xonshDialect integrates with xonsh, whose scripts are Python with
+// $VAR environment access
+type xonshDialect struct{}
+
+func (xonshDialect) Name() string { return "xonsh" }
+
+func (xonshDialect) Script() Script { return &xonshScript{} }
+
+func (xonshDialect) AutoActivate(envName string) string {
+	return fmt.Sprintf(`if $XONSH_INTERACTIVE and 'VIRTUAL_ENV' not in ${...}:
+    uda activate %s
+`, pyQuote(envName))
+}
+
+func (xonshDialect) PipShim() string {
+	return `# pip and pip3 go through uda while a uda env is active
+def _uda_pip_alias(pip):
+    def run(args):
+        if ${...}.get('VIRTUAL_ENV') and ${...}.get('_UDA_ACTIVE_ENV', 'base') != 'base' and args and args[0] in ('install', 'uninstall', 'list', 'freeze', 'show'):
+            @($_UDA_BIN) pip @(args)
+        else:
+            ![@(pip) @(args)]
+    return run
+
+for _uda_pip_name in ('pip', 'pip3'):
+    _uda_pip_path = __xonsh__.commands_cache.locate_binary(_uda_pip_name)
+    if _uda_pip_path:
+        aliases[_uda_pip_name] = _uda_pip_alias(_uda_pip_path)
+`
+}
+
+func (xonshDialect) Init(binaryPath string, prompt Prompt) string {
+	return fmt.Sprintf(`# UDA xonsh integration
+$_UDA_BIN = %s
+if '_UDA_ACTIVE_ENV' not in ${...}:
+    $_UDA_ACTIVE_ENV = 'base'
+if 'UDA_PROMPT_MODIFIER' not in ${...}:
+    $UDA_PROMPT_MODIFIER = %s
+
+# {uda_env} can also be used in a custom $PROMPT
+$PROMPT_FIELDS['uda_env'] = lambda: ${...}.get('UDA_PROMPT_MODIFIER', '')
+if %s and isinstance($PROMPT, str) and '{uda_env}' not in $PROMPT:
+    $PROMPT = '{uda_env}' + $PROMPT
+
+def _uda(args):
+    if args and args[0] in ('activate', 'deactivate'):
+        execx($(@($_UDA_BIN) @(args[0]) --shell xonsh @(args[1:])))
+    else:
+        @($_UDA_BIN) @(args)
+
+aliases['uda'] = _uda
+aliases['conda'] = _uda
+`, pyQuote(binaryPath), pyQuote(prompt.BaseModifier()), pyBool(prompt.Change))
+}
+
+// xonshScript writes xonsh syntax
+type xonshScript struct {
+	b strings.Builder
+}
+
+func (w *xonshScript) HookExt() string { return ".xsh" }
+
+func (w *xonshScript) SetVar(name, value string) {
+	fmt.Fprintf(&w.b, "${...}[%s] = %s\n", pyQuote(name), pyQuote(value))
+}
+
+func (w *xonshScript) UnsetVar(name string) {
+	fmt.Fprintf(&w.b, "${...}.pop(%s, None)\n", pyQuote(name))
+}
+
+func (w *xonshScript) SaveVar(name, to string) {
+	fmt.Fprintf(&w.b, "if %[1]s in ${...}:\n    ${...}[%[2]s] = ${...}[%[1]s]\n", pyQuote(name), pyQuote(to))
+}
+
+func (w *xonshScript) MoveVar(from, to string) {
+	fmt.Fprintf(&w.b, "if %[1]s in ${...}:\n    ${...}[%[2]s] = ${...}.pop(%[1]s)\n", pyQuote(from), pyQuote(to))
+}
+
+func (w *xonshScript) RestoreVar(name, from string) {
+	fmt.Fprintf(&w.b, "if %[2]s in ${...}:\n    ${...}[%[1]s] = ${...}.pop(%[2]s)\nelse:\n    ${...}.pop(%[1]s, None)\n", pyQuote(name), pyQuote(from))
+}
+
+func (w *xonshScript) Source(path string) {
+	fmt.Fprintf(&w.b, "source %s\n", pyQuote(path))
+}
+
+func (w *xonshScript) RemoveActiveBin() {
+	w.b.WriteString("if 'VIRTUAL_ENV' in ${...}:\n    $PATH = [p for p in $PATH if p != $VIRTUAL_ENV + '/bin']\n")
+}
+
+func (w *xonshScript) PrependActiveBin() {
+	w.b.WriteString("$PATH.insert(0, $VIRTUAL_ENV + '/bin')\n")
+}
+
+// SetPrompt has nothing to do: the uda_env prompt field reads UDA_PROMPT_MODIFIER
+func (w *xonshScript) SetPrompt(label string) {}
+
+func (w *xonshScript) String() string {
+	return w.b.String()
+}
+
+// pyQuote quotes a value as a Python string literal; Go's escapes are a
+// subset of Python's
+func pyQuote(value string) string {
+	return strconv.Quote(value)
+}
+
+func pyBool(value bool) string {
+	if value {
+		return "True"
+	}
+	return "False"
+}
@@ -0,0 +1,163 @@
+package shell
+
+import (
+	"fmt"
+
+	"github.com/uda/uda/internal/config"
+	"github.com/uda/uda/internal/env"
+)
+
+// zshDialect integrates with zsh. Activation scripts share the POSIX syntax
+// of bash, but the prompt, hooks and completion are zsh specific.
+type zshDialect struct{}
+
+func (zshDialect) Name() string { return "zsh" }
+
+func (zshDialect) Script() Script { return &posixScript{} }
+
+func (zshDialect) AutoActivate(envName string) string {
+	return fmt.Sprintf(`if [[ -o interactive && -z "${VIRTUAL_ENV-}" ]]; then
+    uda activate %s
+fi
+`, shQuote(envName))
+}
+
+func (zshDialect) PipShim() string { return posixPipShim }
+
+func (zshDialect) Init(binaryPath string, prompt Prompt) string {
+	quotedPath := shQuote(binaryPath)
+	quotedEnvs := shQuote(config.EnvsPath())
+	return fmt.Sprintf(`_UDA_BIN=%[1]s
+_UDA_ENVS_DIR=%[2]s
+_UDA_ACTIVE_ENV="${_UDA_ACTIVE_ENV-base}"
+if [[ -z "${UDA_PROMPT_MODIFIER+x}" ]]; then
+    export UDA_PROMPT_MODIFIER=%[4]s
+fi
+typeset -g _UDA_CHANGE_PROMPT=%[5]s
+typeset -g _UDA_PROMPT_PREFIX="${_UDA_PROMPT_PREFIX-}"
+typeset -g _UDA_PROMPT_TEXT="${_UDA_PROMPT_TEXT-}"
+typeset -g _UDA_CHPWD_ENV="${_UDA_CHPWD_ENV-}"
+
+# Re-apply the prefix, replacing the one added last time if it is still
+# there. With PROMPT_SUBST the modifier is referenced rather than inlined
+# so env names are never evaluated as code.
+_uda_update_prompt() {
+    if [[ "$_UDA_CHANGE_PROMPT" != 1 ]]; then
+        return
+    fi
+    if [[ -n "$_UDA_PROMPT_PREFIX" && "$PROMPT" == "$_UDA_PROMPT_PREFIX"* ]]; then
+        PROMPT="${PROMPT#"$_UDA_PROMPT_PREFIX"}"
+    fi
+    _UDA_PROMPT_TEXT="${UDA_PROMPT_MODIFIER//\%%/%%%%}"
+    if [[ -o prompt_subst ]]; then
+        _UDA_PROMPT_PREFIX='${_UDA_PROMPT_TEXT}'
+    else
+        _UDA_PROMPT_PREFIX="${_UDA_PROMPT_TEXT}"
+    fi
+    PROMPT="${_UDA_PROMPT_PREFIX}${PROMPT}"
+}
+
+_uda_set_prompt() {
+    _uda_update_prompt
+}
+
+# Themes such as oh-my-zsh rebuild PROMPT in their own precmd hooks, so the
+# prefix is re-applied before every prompt and this hook keeps itself last
+_uda_precmd() {
+    _uda_update_prompt
+    precmd_functions=(${precmd_functions:#_uda_precmd} _uda_precmd)
+}
+
+_uda_remove_path_entry() {
+    local entry="$1"
+    if [ -z "$entry" ]; then
+        return
+    fi
+    path=(${path:#$entry})
+}
+
+# Print the env bound to the current directory or one of its parents
+_uda_bound_env() {
+    local dir="$PWD"
+    local name
+    while true; do
+        if [ -f "$dir/%[3]s" ]; then
+            read -r name < "$dir/%[3]s"
+            print -r -- "$name"
+            return
+        fi
+        if [ "$dir" = "/" ]; then
+            return
+        fi
+        dir="${dir:h}"
+    done
+}
+
+# Activate the env bound to a project directory when entering it, and
+# deactivate it again when leaving, unless UDA_CHPWD_ACTIVATE=0
+_uda_chpwd() {
+    if [ "${UDA_CHPWD_ACTIVATE:-1}" = "0" ]; then
+        return
+    fi
+
+    local bound="$(_uda_bound_env)"
+    if [ -n "$bound" ]; then
+        if [ "$bound" != "$_UDA_ACTIVE_ENV" ] && [ -d "$_UDA_ENVS_DIR/$bound" ]; then
+            uda activate "$bound" && _UDA_CHPWD_ENV="$bound"
+        fi
+    elif [ -n "$_UDA_CHPWD_ENV" ]; then
+        if [ "$_UDA_CHPWD_ENV" = "$_UDA_ACTIVE_ENV" ]; then
+            uda deactivate
+        fi
+        _UDA_CHPWD_ENV=""
+    fi
+}
+
+uda() {
+    if [ $# -eq 0 ]; then
+        "$_UDA_BIN"
+        return
+    fi
+
+    local cmd="$1"
+    shift
+
+    case "$cmd" in
+        activate)
+            eval "$("$_UDA_BIN" activate --shell zsh "$@")"
+            ;;
+        deactivate)
+            eval "$("$_UDA_BIN" deactivate --shell zsh)"
+            ;;
+        *)
+            "$_UDA_BIN" "$cmd" "$@"
+            ;;
+    esac
+}
+
+# Completion is computed by the binary so it follows the command tree
+_uda() {
+    local -a candidates
+    candidates=("${(@f)$("$_UDA_BIN" __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    candidates=(${candidates:#})
+    if (( ${#candidates} )); then
+        compadd -- "${candidates[@]}"
+    else
+        _files
+    fi
+}
+
+autoload -Uz add-zsh-hook
+add-zsh-hook precmd _uda_precmd
+add-zsh-hook chpwd _uda_chpwd
+
+if (( $+functions[compdef] )); then
+    compdef _uda uda
+fi
+
+_uda_update_prompt
+
+# Alias for conda compatibility
+alias conda=uda
+`, quotedPath, quotedEnvs, env.BindFile, shQuote(prompt.BaseModifier()), changeFlag(prompt))
+}
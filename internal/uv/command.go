@@ -0,0 +1,32 @@
+package uv
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// killDelay is how long a cancelled command gets to exit before it is killed
+const killDelay = 5 * time.Second
+
+// Command prepares a subprocess bound to ctx that may use the terminal. It
+// stays in uda's process group, so it can prompt on the terminal and gets
+// Ctrl-C directly; when ctx is cancelled otherwise, e.g. by --timeout, it is
+// asked to terminate.
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	setTerminate(cmd)
+	cmd.WaitDelay = killDelay
+	return cmd
+}
+
+// BackgroundCommand prepares a non-interactive subprocess bound to ctx. The
+// process runs in its own process group, so when ctx is cancelled the whole
+// group is terminated, including anything it started itself. It must not
+// read from the terminal.
+func BackgroundCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
+	cmd.WaitDelay = killDelay
+	return cmd
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package uv
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setTerminate makes cancellation send SIGTERM to the process
+func setTerminate(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+}
+
+// setProcessGroup starts cmd in a new process group and makes cancellation
+// send SIGTERM to that group
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+}
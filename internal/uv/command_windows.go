@@ -0,0 +1,11 @@
+//go:build windows
+
+package uv
+
+import "os/exec"
+
+// setTerminate keeps the default cancellation, which kills the process
+func setTerminate(cmd *exec.Cmd) {}
+
+// setProcessGroup keeps the default cancellation, which kills the process
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,141 @@
+package uv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/uda/uda/internal/pyver"
+)
+
+// PythonInstallation is one entry of `uv python list --output-format json`
+type PythonInstallation struct {
+	Key            string `json:"key"`
+	Version        string `json:"version"`
+	Path           string `json:"path"`
+	Implementation string `json:"implementation"`
+	Variant        string `json:"variant"`
+}
+
+// Installed reports whether the interpreter is present on disk
+func (p PythonInstallation) Installed() bool {
+	return p.Path != ""
+}
+
+// ListPythons lists interpreters known to uv, optionally filtered by a version request
+func ListPythons(ctx context.Context, request string, onlyInstalled bool) ([]PythonInstallation, error) {
+	uv, err := FindUv()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"python", "list", "--output-format", "json"}
+	if onlyInstalled {
+		args = append(args, "--only-installed")
+	}
+	return listPythons(ctx, uv, request, args)
+}
+
+// ListManagedPythons lists the installed interpreters uv manages itself,
+// which are the ones `uv python uninstall` removes
+func ListManagedPythons(ctx context.Context, request string) ([]PythonInstallation, error) {
+	uv, err := FindUv()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"python", "list", "--output-format", "json", "--only-installed", "--python-preference", "only-managed"}
+	return listPythons(ctx, uv, request, args)
+}
+
+func listPythons(ctx context.Context, uv string, request string, args []string) ([]PythonInstallation, error) {
+	if request != "" {
+		args = append(args, request)
+	}
+
+	cmd := BackgroundCommand(ctx, uv, args...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Python versions: %w", err)
+	}
+
+	var pythons []PythonInstallation
+	if err := json.Unmarshal(out, &pythons); err != nil {
+		return nil, fmt.Errorf("failed to parse uv python list output: %w", err)
+	}
+	return pythons, nil
+}
+
+// FindPython returns the path of the interpreter uv would use for a version request
+func FindPython(ctx context.Context, request string) (string, error) {
+	uv, err := FindUv()
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"python", "find"}
+	if request != "" {
+		args = append(args, request)
+	}
+
+	cmd := BackgroundCommand(ctx, uv, args...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no Python found for %s: %w", request, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// UninstallPython removes uv-managed interpreters matching a version request
+func UninstallPython(ctx context.Context, request string) error {
+	return RunUv(ctx, "python", "uninstall", request)
+}
+
+// ResolvePython resolves a PEP 440 range such as ">=3.10,<3.13" to the newest
+// matching CPython release, preferring interpreters that are already installed.
+// Plain versions are returned unchanged.
+func ResolvePython(ctx context.Context, request string) (string, error) {
+	if !pyver.IsSpecifier(request) {
+		return request, nil
+	}
+
+	spec, err := pyver.ParseSpecifier(request)
+	if err != nil {
+		return "", err
+	}
+
+	pythons, err := ListPythons(ctx, "", false)
+	if err != nil {
+		return "", err
+	}
+
+	var best, bestInstalled *pyver.Version
+	for _, p := range pythons {
+		if p.Implementation != "cpython" || p.Variant != "" && p.Variant != "default" {
+			continue
+		}
+		v, err := pyver.Parse(p.Version)
+		if err != nil || v.Prerelease || !spec.Contains(v) {
+			continue
+		}
+
+		if best == nil || v.Compare(*best) > 0 {
+			best = &v
+		}
+		if p.Installed() && (bestInstalled == nil || v.Compare(*bestInstalled) > 0) {
+			bestInstalled = &v
+		}
+	}
+
+	if bestInstalled != nil {
+		return bestInstalled.String(), nil
+	}
+	if best != nil {
+		return best.String(), nil
+	}
+	return "", fmt.Errorf("no Python version matches %s", request)
+}
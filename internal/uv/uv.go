@@ -1,14 +1,17 @@
 package uv
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/uda/uda/internal/config"
 	"github.com/uda/uda/internal/mirror"
@@ -48,13 +51,13 @@ func FindUv() (string, error) {
 	return "", fmt.Errorf("uv not found, run 'uda self install' to install")
 }
 
-func RunUv(args ...string) error {
+func RunUv(ctx context.Context, args ...string) error {
 	uv, err := FindUv()
 	if err != nil {
 		return err
 	}
 
-	cmd := exec.Command(uv, args...)
+	cmd := Command(ctx, uv, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
@@ -63,18 +66,43 @@ func RunUv(args ...string) error {
 }
 
 func GetPythonPath(envName string) string {
+	return VenvPython(config.EnvPath(envName))
+}
+
+// VenvPython returns the interpreter path inside a venv directory
+func VenvPython(envPath string) string {
 	if runtime.GOOS == "windows" {
-		return filepath.Join(config.EnvPath(envName), "Scripts", "python.exe")
+		return filepath.Join(envPath, "Scripts", "python.exe")
 	}
-	return filepath.Join(config.EnvPath(envName), "bin", "python")
+	return filepath.Join(envPath, "bin", "python")
+}
+
+// httpClient bounds connecting and waiting for response headers but not the
+// download itself, which is limited by the context instead
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   30 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
 }
 
 // Install downloads and installs uv binary with mirror support
-func Install() error {
-	return installWithMirror(false)
+func Install(ctx context.Context) error {
+	return installWithMirror(ctx, false)
+}
+
+// download starts a GET request for url bound to ctx
+func download(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return httpClient.Do(req)
 }
 
-func installWithMirror(fallbackToOfficial bool) error {
+func installWithMirror(ctx context.Context, fallbackToOfficial bool) error {
 	uvPath := config.UvPath()
 
 	// Ensure directory exists
@@ -89,7 +117,7 @@ func installWithMirror(fallbackToOfficial bool) error {
 		if mirrorURL == "" {
 			// Try to find working mirror
 			var err error
-			mirrorURL, err = mirror.FindWorkingMirror()
+			mirrorURL, err = mirror.FindWorkingMirror(ctx)
 			if err != nil {
 				mirrorURL = "https://astral.sh" // Fallback to official
 			}
@@ -117,12 +145,12 @@ func installWithMirror(fallbackToOfficial bool) error {
 			return fmt.Errorf("failed to create tar file: %w", err)
 		}
 
-		resp, err := http.Get(uvURL)
+		resp, err := download(ctx, uvURL)
 		if err != nil {
 			out.Close()
-			if mirrorURL != "" && mirrorURL != "https://astral.sh" {
+			if mirrorURL != "" && mirrorURL != "https://astral.sh" && ctx.Err() == nil {
 				fmt.Println("Mirror failed, trying official source...")
-				return installWithMirror(true)
+				return installWithMirror(ctx, true)
 			}
 			return fmt.Errorf("failed to download uv: %w", err)
 		}
@@ -132,7 +160,7 @@ func installWithMirror(fallbackToOfficial bool) error {
 			out.Close()
 			if mirrorURL != "" && mirrorURL != "https://astral.sh" {
 				fmt.Println("Mirror failed, trying official source...")
-				return installWithMirror(true)
+				return installWithMirror(ctx, true)
 			}
 			return fmt.Errorf("failed to download uv: %s", resp.Status)
 		}
@@ -144,7 +172,7 @@ func installWithMirror(fallbackToOfficial bool) error {
 		}
 
 		// Extract tar.gz
-		cmd := exec.Command("tar", "-xzf", tarPath, "-C", tmpDir)
+		cmd := BackgroundCommand(ctx, "tar", "-xzf", tarPath, "-C", tmpDir)
 		if err := cmd.Run(); err != nil {
 			return fmt.Errorf("failed to extract tar file: %w", err)
 		}
@@ -175,11 +203,11 @@ func installWithMirror(fallbackToOfficial bool) error {
 		}
 	} else {
 		// Download single binary
-		resp, err := http.Get(uvURL)
+		resp, err := download(ctx, uvURL)
 		if err != nil {
-			if mirrorURL != "" && mirrorURL != "https://astral.sh" {
+			if mirrorURL != "" && mirrorURL != "https://astral.sh" && ctx.Err() == nil {
 				fmt.Println("Mirror failed, trying official source...")
-				return installWithMirror(true)
+				return installWithMirror(ctx, true)
 			}
 			return fmt.Errorf("failed to download uv: %w", err)
 		}
@@ -188,7 +216,7 @@ func installWithMirror(fallbackToOfficial bool) error {
 		if resp.StatusCode != 200 {
 			if mirrorURL != "" && mirrorURL != "https://astral.sh" {
 				fmt.Println("Mirror failed, trying official source...")
-				return installWithMirror(true)
+				return installWithMirror(ctx, true)
 			}
 			return fmt.Errorf("failed to download uv: %s", resp.Status)
 		}
@@ -257,7 +285,7 @@ func getUvDownloadURL(mirrorURL string) string {
 }
 
 // InstallPython installs a specific Python version
-func InstallPython(version string) error {
+func InstallPython(ctx context.Context, version string) error {
 	// First check if Python is already installed
 	uv, err := FindUv()
 	if err != nil {
@@ -265,7 +293,7 @@ func InstallPython(version string) error {
 	}
 
 	// Run uv python install
-	cmd := exec.Command(uv, "python", "install", version)
+	cmd := Command(ctx, uv, "python", "install", version)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
@@ -273,12 +301,35 @@ func InstallPython(version string) error {
 }
 
 // RunUvWithPython runs uv with a specific Python interpreter
-func RunUvWithPython(pythonPath string, args ...string) error {
+func RunUvWithPython(ctx context.Context, pythonPath string, args ...string) error {
 	uv, err := FindUv()
 	if err != nil {
 		return err
 	}
 
+	cmd := Command(ctx, uv, pythonArgs(pythonPath, args)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+
+	return cmd.Run()
+}
+
+// OutputUvWithPython runs uv with a specific Python interpreter and returns its stdout
+func OutputUvWithPython(ctx context.Context, pythonPath string, args ...string) ([]byte, error) {
+	uv, err := FindUv()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := BackgroundCommand(ctx, uv, pythonArgs(pythonPath, args)...)
+	cmd.Stderr = os.Stderr
+
+	return cmd.Output()
+}
+
+// pythonArgs prepends a --python flag to args unless one is already present
+func pythonArgs(pythonPath string, args []string) []string {
 	// Insert --python flag after the uv command
 	fullArgs := []string{}
 	foundPython := false
@@ -293,11 +344,5 @@ func RunUvWithPython(pythonPath string, args ...string) error {
 	if !foundPython && pythonPath != "" {
 		fullArgs = append([]string{"--python", pythonPath}, fullArgs...)
 	}
-
-	cmd := exec.Command(uv, fullArgs...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	return cmd.Run()
+	return fullArgs
 }
@@ -1,9 +1,13 @@
 package uv
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetUvDownloadURLLinuxWithMirror(t *testing.T) {
@@ -30,3 +34,24 @@ func TestGetUvDownloadURLLinuxIgnoresAstralShMirror(t *testing.T) {
 		t.Fatalf("expected official mirror fallback, got %s", got)
 	}
 }
+
+func TestCommandCancelStopsProcessGroup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are unix only")
+	}
+
+	marker := filepath.Join(t.TempDir(), "survived")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The grandchild writes the marker unless it is stopped with the group
+	cmd := BackgroundCommand(ctx, "sh", "-c", "(sleep 1; touch "+marker+") & wait")
+	if err := cmd.Run(); err == nil {
+		t.Fatal("expected the cancelled command to fail")
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("expected the grandchild to be stopped with the process group")
+	}
+}